@@ -0,0 +1,206 @@
+package radius
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Acct-Status-Type values
+const (
+	AcctStatusStart   uint32 = 1
+	AcctStatusStop    uint32 = 2
+	AcctStatusInterim uint32 = 3
+	AcctStatusOn      uint32 = 7
+	AcctStatusOff     uint32 = 8
+)
+
+// Acct-Terminate-Cause values
+const (
+	TermUserRequest        uint32 = 1
+	TermLostCarrier        uint32 = 2
+	TermLostService        uint32 = 3
+	TermIdleTimeout        uint32 = 4
+	TermSessionTimeout     uint32 = 5
+	TermAdminReset         uint32 = 6
+	TermAdminReboot        uint32 = 7
+	TermPortError          uint32 = 8
+	TermNASError           uint32 = 9
+	TermNASRequest         uint32 = 10
+	TermNASReboot          uint32 = 11
+	TermPortUnneeded       uint32 = 12
+	TermPortPreempted      uint32 = 13
+	TermPortSuspended      uint32 = 14
+	TermServiceUnavailable uint32 = 15
+	TermCallback           uint32 = 16
+	TermUserError          uint32 = 17
+	TermHostRequest        uint32 = 18
+)
+
+// Accounting session, builds Accounting-Requests for one session
+type Session struct {
+	mu      sync.Mutex       // Guards session state
+	id      string           // Acct-Session-Id
+	secret  []byte           // Radius shared secret
+	opts    []PacketOpt      // Options applied to every packet
+	now     func() time.Time // Clock, nil - time.Now
+	start   time.Time        // Session start time
+	inOct   uint64           // Input octets
+	outOct  uint64           // Output octets
+	inPkt   uint32           // Input packets
+	outPkt  uint32           // Output packets
+	started bool             // Start was sent
+	stopped bool             // Stop was sent
+}
+
+// Create session, opts are added to every packet (User-Name, NAS-IP-Address, etc.)
+func NewSession(secret []byte, id string, opts ...PacketOpt) *Session {
+	return &Session{
+		id:     id,
+		secret: secret,
+		opts:   opts,
+	}
+}
+
+// Set clock used for timestamps, for reproducible packets
+func (s *Session) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+func (s *Session) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+func (s *Session) GetID() string {
+	return s.id
+}
+
+func (s *Session) GetStartTime() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.start
+}
+
+// Set counters to current absolute values
+func (s *Session) Update(inOctets, outOctets uint64, inPackets, outPackets uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inOct = inOctets
+	s.outOct = outOctets
+	s.inPkt = inPackets
+	s.outPkt = outPackets
+}
+
+// Add to counters
+func (s *Session) Add(inOctets, outOctets uint64, inPackets, outPackets uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inOct += inOctets
+	s.outOct += outOctets
+	s.inPkt += inPackets
+	s.outPkt += outPackets
+}
+
+// Accounting-Request with Acct-Status-Type = Start
+func (s *Session) Start() (*Packet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return nil, errors.New("Session already started")
+	}
+	now := s.clock()
+	p, err := s.newPacket(AcctStatusStart, now)
+	if err != nil {
+		return nil, err
+	}
+	s.start = now
+	s.started = true
+	return p, nil
+}
+
+// Accounting-Request with Acct-Status-Type = Interim-Update
+func (s *Session) Interim() (*Packet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started || s.stopped {
+		return nil, errors.New("Session not active")
+	}
+	p, err := s.newPacket(AcctStatusInterim, s.clock())
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Accounting-Request with Acct-Status-Type = Stop
+func (s *Session) Stop(cause uint32) (*Packet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started || s.stopped {
+		return nil, errors.New("Session not active")
+	}
+	now := s.clock()
+	p, err := s.newPacket(AcctStatusStop, now)
+	if err != nil {
+		return nil, err
+	}
+	if cause != 0 {
+		if err = p.addStd(AttrAcctTerminateCause, 0, cause); err != nil {
+			return nil, err
+		}
+	}
+	s.stopped = true
+	return p, nil
+}
+
+func (s *Session) newPacket(st uint32, now time.Time) (p *Packet, err error) {
+	if p, err = NewAccountingRequest(s.secret, s.opts...); err != nil {
+		return
+	}
+	if err = p.addStd(AttrAcctStatusType, 0, st); err != nil {
+		return
+	}
+	if err = p.addStd(AttrAcctSessionID, 0, s.id); err != nil {
+		return
+	}
+	if err = p.addStd(AttrEventTimestamp, 0, now); err != nil {
+		return
+	}
+	if err = p.addStd(AttrAcctDelayTime, 0, uint32(0)); err != nil {
+		return
+	}
+	if st == AcctStatusStart {
+		return
+	}
+	// counters and session time only make sense after start
+	if err = p.addStd(AttrAcctSessionTime, 0, uint32(now.Sub(s.start)/time.Second)); err != nil {
+		return
+	}
+	if err = p.addCounter(AttrAcctInputOctets, AttrAcctInputGigawords, s.inOct); err != nil {
+		return
+	}
+	if err = p.addCounter(AttrAcctOutputOctets, AttrAcctOutputGigawords, s.outOct); err != nil {
+		return
+	}
+	if err = p.addStd(AttrAcctInputPackets, 0, s.inPkt); err != nil {
+		return
+	}
+	err = p.addStd(AttrAcctOutputPackets, 0, s.outPkt)
+	return
+}
+
+// 64 bit counter as octets + gigawords (RFC 2869)
+func (p *Packet) addCounter(oct, giga AttrType, v uint64) error {
+	if err := p.addStd(oct, 0, uint32(v)); err != nil {
+		return err
+	}
+	if v>>32 == 0 {
+		return nil
+	}
+	return p.addStd(giga, 0, uint32(v>>32))
+}
@@ -2,6 +2,7 @@ package radius
 
 import (
 	"encoding/binary"
+	"fmt"
 	"net"
 	"time"
 )
@@ -12,13 +13,182 @@ type Attr struct {
 	vid   VendorID    // Vendor ID
 	vtype VendorType  // Vendor Type
 	vlen  byte        // Vendor len
+	vf    *vendorFmt  // VSA header format, nil - standard
 	tag   byte        // Tag for tagged attrs
 	data  []byte      // Raw attr data without tag
+	raw   []byte      // Wire form of whole attr in exact mode, shared by packed VSAs
+	cont  bool        // Not first VSA of packed attr, wire form is in first
+	clear bool        // Value of encrypted attr is plain text, encrypted on serialize
+	cdata []byte      // Encrypted value of clear attr from last serialize
 	edata interface{} // Evaluated data
 	ad    *AttrData   // Attribute data from dict
 	pkt   *Packet     // Packet which this attr is belongs
 }
 
+// Attr len on wire, including VSA header and tag
+func (a *Attr) wireLen() int {
+	if a.cont {
+		return 0
+	}
+	if a.raw != nil {
+		return len(a.raw)
+	}
+	hl, pl := a.hdrLen(), a.evsLen()
+	if a.isConcat() {
+		n, max := a.dataLen()+pl, 255-hl
+		if n == 0 {
+			return hl
+		}
+		return n + hl*((n+max-1)/max)
+	}
+	n := a.dataLen() + hl + pl
+	if a.ad.IsTagged() {
+		n++
+	}
+	return n
+}
+
+// value len on wire without tag
+func (a *Attr) dataLen() int {
+	if a.clear {
+		return encLen(a.attrData().GetEnc(), len(a.data))
+	}
+	return len(a.data)
+}
+
+// value on wire without tag, encrypted value for clear attr
+func (a *Attr) wireData() []byte {
+	if a.clear {
+		return a.cdata
+	}
+	return a.data
+}
+
+// header len of each wire attr
+func (a *Attr) hdrLen() int {
+	switch {
+	case a.IsVSA():
+		return 6 + a.vf.hdrLen()
+	case isLongExtended(a.atype):
+		return 4
+	case isExtended(a.atype):
+		return 3
+	}
+	return 2
+}
+
+// long concat attr, VSA with continuation flag or Long-Extended-Type attr
+// is split in several wire attrs
+func (a *Attr) isConcat() bool {
+	switch {
+	case a.IsVSA():
+		return a.vf.cont()
+	case isLongExtended(a.atype):
+		return true
+	}
+	return a.attrData().IsConcat()
+}
+
+// append next part of value split across wire attrs, value is copied
+// out of parse buffer on first join
+func (a *Attr) joinData(b []byte) {
+	a.data = append(a.data[:len(a.data):len(a.data)], b...)
+	a.setLen()
+}
+
+// attr does not fit in one wire attr and can't be split
+func (a *Attr) tooLong() bool {
+	return a.wireLen() > 255 && !a.isConcat()
+}
+
+func (a *Attr) setLen() {
+	n := a.wireLen()
+	if n > 255 { // len of first wire attr of split attr
+		n = 255
+	}
+	a.alen = byte(n)
+	if a.IsVSA() {
+		a.vlen = byte(n - 6)
+	}
+}
+
+// append attr in wire format
+func (a *Attr) appendWire(b []byte) ([]byte, error) {
+	if a.cont {
+		return b, nil
+	}
+	if a.raw != nil {
+		return append(b, a.raw...), nil
+	}
+	if a.tooLong() {
+		return b, errAttrTooLong
+	}
+	if a.isConcat() {
+		return a.appendConcat(b), nil
+	}
+	if a.IsVSA() {
+		b = append(b, byte(a.atype), byte(a.wireLen()))
+		b = binary.BigEndian.AppendUint32(b, uint32(a.vid))
+		return a.appendVendor(b), nil
+	}
+	b = a.appendHdr(b, a.wireLen()-a.hdrLen(), false)
+	b = a.appendEVS(b, a.evsLen())
+	if a.ad.IsTagged() {
+		b = append(b, a.tag)
+	}
+	return append(b, a.wireData()...), nil
+}
+
+// append vendor type, len and value of VSA
+func (a *Attr) appendVendor(b []byte) []byte {
+	b = a.vf.appendHdr(b, a.vtype, a.wireLen()-6)
+	if a.ad.IsTagged() {
+		b = append(b, a.tag)
+	}
+	return append(b, a.wireData()...)
+}
+
+// append header of wire attr with n value bytes, more sets continuation
+// flag of VSA or More flag of Long-Extended-Type attr
+func (a *Attr) appendHdr(b []byte, n int, more bool) []byte {
+	n += a.hdrLen()
+	b = append(b, byte(a.atype), byte(n))
+	switch {
+	case a.IsVSA():
+		b = binary.BigEndian.AppendUint32(b, uint32(a.vid))
+		b = a.vf.appendHdr(b, a.vtype, n-6)
+		if more {
+			b[len(b)-1] = vsaMore
+		}
+	case isLongExtended(a.atype):
+		b = append(b, a.GetExtType(), 0)
+		if more {
+			b[len(b)-1] = extMore
+		}
+	case isExtended(a.atype):
+		b = append(b, a.GetExtType())
+	}
+	return b
+}
+
+// split value in wire attrs of at most 255 bytes, parts but last
+// have continuation flag, EVS header is in first part only
+func (a *Attr) appendConcat(b []byte) []byte {
+	data, hl, pl := a.wireData(), a.hdrLen(), a.evsLen()
+	for {
+		n := len(data)
+		if n > 255-hl-pl {
+			n = 255 - hl - pl
+		}
+		b = a.appendHdr(b, n+pl, n < len(data))
+		b, pl = a.appendEVS(b, pl), 0
+		b = append(b, data[:n]...)
+		if data = data[n:]; len(data) == 0 {
+			return b
+		}
+	}
+}
+
 func (a *Attr) IsVSA() bool {
 	return (a.atype == AttrVSA)
 }
@@ -39,6 +209,23 @@ func (a *Attr) GetAttrData() *AttrData {
 	return a.ad
 }
 
+// Dictionary name or Attr-N / VSA-V-N for unknown attrs
+func (a *Attr) GetName() string {
+	if a.ad != nil {
+		return a.ad.name
+	}
+	if a.IsVSA() {
+		return fmt.Sprintf("VSA-%d-%d", a.vid, a.vtype)
+	}
+	if a.IsEVS() {
+		return fmt.Sprintf("Attr-%d.%d.%d.%d", a.atype, ExtEVS, a.vid, a.vtype)
+	}
+	if a.IsExtended() {
+		return fmt.Sprintf("Attr-%d.%d", a.atype, a.vtype)
+	}
+	return fmt.Sprintf("Attr-%d", a.atype)
+}
+
 func (a *Attr) GetAttrPacket() *Packet {
 	return a.pkt
 }
@@ -47,61 +234,133 @@ func (a *Attr) GetData() []byte {
 	return a.data
 }
 
+// Decoded value, encrypted value is decrypted with packet secret and
+// Authenticator (see Attr.Decrypt) unless Packet.SetDecrypt is off,
+// raw data if it can't be decrypted or decoded
 func (a *Attr) GetEData() interface{} {
 	if a.edata != nil {
 		return a.edata
 	}
-	if a.ad == nil {
-		a.edata = a.data
+	if a.ad.GetEnc() == AttrEncNone {
+		a.edata = decodeData(a.ad, a.data)
 		return a.edata
 	}
-	if a.ad.enc != AttrEncNone {
-		a.edata = a.data
-		return a.edata
+	if a.pkt != nil && a.pkt.nodec {
+		return a.data
+	}
+	v, err := a.Decrypt()
+	if err != nil {
+		return a.data // not cached, secret or Authenticator may be set later
 	}
-	switch a.ad.dtype {
+	a.edata = decodeValue(a.ad, v)
+	return a.edata
+}
+
+// decode attr value by dictionary data, raw data if it can't be decoded
+func decodeData(ad *AttrData, data []byte) interface{} {
+	if ad == nil || ad.enc != AttrEncNone {
+		return data
+	}
+	return decodeValue(ad, data)
+}
+
+// decode plain attr value by data type
+func decodeValue(ad *AttrData, data []byte) (v interface{}) {
+	switch ad.dtype {
 	case DTypeString:
-		a.edata = string(a.data)
+		v = string(data)
 	case DTypeIP4:
-		if len(a.data) == 4 {
-			a.edata = net.IP(a.data)
+		if len(data) == 4 {
+			v = net.IP(data)
+		}
+	case DTypeIP4Pfx, DTypeIP6Pfx:
+		if n, ok := decodePrefix(data, ad.dtype == DTypeIP6Pfx); ok {
+			v = n
 		}
 	case DTypeInt:
-		if len(a.data) == 4 {
-			a.edata = binary.BigEndian.Uint32(a.data)
+		if n, ok := intData(ad, data); ok {
+			v = n
+		}
+	case DTypeSInt:
+		if len(data) == 4 {
+			v = int32(binary.BigEndian.Uint32(data))
 		}
 	case DTypeInt64:
-		if len(a.data) == 8 {
-			a.edata = binary.BigEndian.Uint64(a.data)
+		if len(data) == 8 {
+			v = binary.BigEndian.Uint64(data)
 		}
 	case DTypeDate:
-		if len(a.data) == 4 {
-			t := binary.BigEndian.Uint32(a.data)
-			a.edata = time.Unix(int64(t), 0)
+		if len(data) == 4 {
+			t := binary.BigEndian.Uint32(data) // unsigned, valid up to 2106
+			v = time.Unix(int64(t), 0)
+		}
+	case DTypeSNTP:
+		if len(data) == 4 {
+			v = ntpTime(binary.BigEndian.Uint32(data))
 		}
 	case DTypeIfID:
-		if len(a.data) == 8 {
-			a.edata = binary.BigEndian.Uint64(a.data)
+		if len(data) == 8 {
+			v = binary.BigEndian.Uint64(data)
 		}
 	case DTypeIP6:
-		if len(a.data) == 16 {
-			a.edata = net.IP(a.data)
+		if len(data) == 16 {
+			v = net.IP(data)
 		}
 	case DTypeByte:
-		if len(a.data) == 1 {
-			a.edata = a.data[0]
+		if len(data) == 1 {
+			v = data[0]
 		}
 	case DTypeEth:
-		if len(a.data) == 6 {
-			a.edata = net.HardwareAddr(a.data)
+		if len(data) == 6 {
+			v = net.HardwareAddr(data)
 		}
 	case DTypeShort:
-		if len(a.data) == 2 {
-			a.edata = binary.BigEndian.Uint16(a.data)
+		if len(data) == 2 {
+			v = binary.BigEndian.Uint16(data)
+		}
+	case DTypeTLV:
+		if tlvs, ok := decodeTLVs(data, ad.attrPath()); ok {
+			v = tlvs
 		}
 	}
-	if a.edata == nil {
-		a.edata = a.data
+	if v == nil {
+		v = data
 	}
-	return a.edata
+	return
+}
+
+// Set attr value in place, value types are the same as for Packet.AddAttr.
+// Tag is kept, packed VSA is unpacked on serialize.
+func (a *Attr) Set(value interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if ad := a.attrData(); ad == nil {
+		av, ok := value.([]byte)
+		if !ok {
+			return errInvalidFormat
+		}
+		data = av
+	} else if data, err = valueConv(ad, value); err != nil {
+		return err
+	} else if data, err = tagValue(ad, data); err != nil {
+		return err
+	}
+	enc := a.attrData().GetEnc()
+	if !encLenOK(enc, len(data)) {
+		return errEncLen
+	}
+	clear := encSupported(enc)
+	na := Attr{atype: a.atype, vf: a.vf, ad: a.ad, data: data, clear: clear}
+	if na.tooLong() {
+		return errAttrTooLong
+	}
+	a.dropRaw()
+	a.data = data
+	a.clear, a.cdata = clear, nil
+	a.edata = nil
+	a.setLen()
+	return nil
 }
@@ -0,0 +1,96 @@
+package radius
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// Attr builder by dictionary name, first error is kept until Build:
+//
+//	a := radius.NewAttr("Tunnel-Private-Group-ID").Tag(1).String("vlan100").MustBuild()
+//	p.AddAttrSimple(a)
+type AttrBuilder struct {
+	ad    *AttrData
+	tag   byte
+	value interface{}
+	err   error
+}
+
+// Builder of attr known to dictionary or builtin standard attr
+func NewAttr(name string) *AttrBuilder {
+	ad := stdAttrByName(name)
+	if ad == nil {
+		return &AttrBuilder{err: errors.New("Attribute not found: " + name)}
+	}
+	return NewAttrData(ad)
+}
+
+// Builder of attr by attr data
+func NewAttrData(ad *AttrData) *AttrBuilder {
+	switch {
+	case ad == nil:
+		return &AttrBuilder{err: errors.New("Attribute data empty")}
+	case ad.path != "":
+		return &AttrBuilder{err: errors.New("Nested TLV is not attribute: " + ad.name)}
+	}
+	return &AttrBuilder{ad: ad}
+}
+
+// Tag of tagged attr, ignored for untagged
+func (b *AttrBuilder) Tag(tag byte) *AttrBuilder {
+	if tag > 0x1f && b.err == nil {
+		b.err = errors.New("Tag out of range")
+	}
+	b.tag = tag
+	return b
+}
+
+// Value of any type accepted by Packet.AddAttr for attr data type
+func (b *AttrBuilder) Value(v interface{}) *AttrBuilder {
+	b.value = v
+	return b
+}
+
+func (b *AttrBuilder) String(v string) *AttrBuilder {
+	return b.Value(v)
+}
+
+func (b *AttrBuilder) Bytes(v []byte) *AttrBuilder {
+	return b.Value(v)
+}
+
+func (b *AttrBuilder) Uint32(v uint32) *AttrBuilder {
+	return b.Value(v)
+}
+
+func (b *AttrBuilder) Uint64(v uint64) *AttrBuilder {
+	return b.Value(v)
+}
+
+func (b *AttrBuilder) IP(v net.IP) *AttrBuilder {
+	return b.Value(v)
+}
+
+func (b *AttrBuilder) Time(v time.Time) *AttrBuilder {
+	return b.Value(v)
+}
+
+// Attr ready for Packet.AddAttrSimple
+func (b *AttrBuilder) Build() (*Attr, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.value == nil {
+		return nil, errors.New("Attribute value empty: " + b.ad.name)
+	}
+	return buildAttr(b.ad.atype, b.ad.vid, b.ad.vtype, b.ad, b.tag, b.value)
+}
+
+func (b *AttrBuilder) MustBuild() *Attr {
+	a, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
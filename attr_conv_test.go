@@ -0,0 +1,163 @@
+package radius
+
+import (
+	"bytes"
+	"math"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestAttrConv(t *testing.T) {
+	mac := net.HardwareAddr{0, 0x11, 0x22, 0x33, 0x44, 0x55}
+	_, n4, _ := net.ParseCIDR("192.0.2.0/24")
+	_, n6, _ := net.ParseCIDR("2001:db8::/32")
+	tests := []struct {
+		name string
+		dt   AttrDType
+		v    interface{}
+		want []byte // nil - error expected
+	}{
+		{"raw bytes", DTypeRaw, []byte{1, 2}, []byte{1, 2}},
+		{"raw string", DTypeRaw, "ab", []byte("ab")},
+		{"raw int", DTypeRaw, 1, nil},
+
+		{"string", DTypeString, "ab", []byte("ab")},
+		{"string bytes", DTypeString, []byte("ab"), []byte("ab")},
+		{"string int", DTypeString, 1, nil},
+
+		{"ip4", DTypeIP4, net.IPv4(192, 0, 2, 1), []byte{192, 0, 2, 1}},
+		{"ip4 netip", DTypeIP4, netip.MustParseAddr("192.0.2.1"), []byte{192, 0, 2, 1}},
+		{"ip4 mapped", DTypeIP4, netip.MustParseAddr("::ffff:192.0.2.1"), []byte{192, 0, 2, 1}},
+		{"ip4 v6", DTypeIP4, net.ParseIP("2001:db8::1"), nil},
+		{"ip4 string", DTypeIP4, "192.0.2.1", nil},
+
+		{"ip4 pfx ipnet", DTypeIP4Pfx, n4, []byte{0, 24, 192, 0, 2, 0}},
+		{"ip4 pfx ipnet value", DTypeIP4Pfx, *n4, []byte{0, 24, 192, 0, 2, 0}},
+		{"ip4 pfx netip", DTypeIP4Pfx, netip.MustParsePrefix("192.0.2.0/24"), []byte{0, 24, 192, 0, 2, 0}},
+		{"ip4 pfx v6", DTypeIP4Pfx, n6, nil},
+		{"ip4 pfx invalid", DTypeIP4Pfx, netip.Prefix{}, nil},
+
+		{"ip6 pfx ipnet", DTypeIP6Pfx, n6, []byte{0, 32, 0x20, 0x01, 0x0d, 0xb8}},
+		{"ip6 pfx netip", DTypeIP6Pfx, netip.MustParsePrefix("2001:db8::/32"), []byte{0, 32, 0x20, 0x01, 0x0d, 0xb8}},
+		{"ip6 pfx v4", DTypeIP6Pfx, n4, nil},
+
+		{"int", DTypeInt, 7, []byte{0, 0, 0, 7}},
+		{"int uint32", DTypeInt, uint32(math.MaxUint32), []byte{0xff, 0xff, 0xff, 0xff}},
+		{"int uint8", DTypeInt, uint8(7), []byte{0, 0, 0, 7}},
+		{"int int64", DTypeInt, int64(7), []byte{0, 0, 0, 7}},
+		{"int negative", DTypeInt, -1, nil},
+		{"int overflow", DTypeInt, uint64(math.MaxUint32) + 1, nil},
+		{"int string", DTypeInt, "7", nil},
+
+		{"int64", DTypeInt64, uint64(math.MaxUint64), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"int64 int", DTypeInt64, 1, []byte{0, 0, 0, 0, 0, 0, 0, 1}},
+		{"int64 negative", DTypeInt64, int64(-1), nil},
+
+		{"sint", DTypeSInt, -2, []byte{0xff, 0xff, 0xff, 0xfe}},
+		{"sint int32", DTypeSInt, int32(math.MinInt32), []byte{0x80, 0, 0, 0}},
+		{"sint uint16", DTypeSInt, uint16(7), []byte{0, 0, 0, 7}},
+		{"sint overflow", DTypeSInt, int64(math.MaxInt32) + 1, nil},
+		{"sint underflow", DTypeSInt, int64(math.MinInt32) - 1, nil},
+
+		{"date time", DTypeDate, time.Unix(0x01020304, 0), []byte{1, 2, 3, 4}},
+		{"date int", DTypeDate, 0x01020304, []byte{1, 2, 3, 4}},
+		{"date uint32", DTypeDate, uint32(0x01020304), []byte{1, 2, 3, 4}},
+		{"date negative", DTypeDate, time.Unix(-1, 0), nil},
+		{"date overflow", DTypeDate, int64(math.MaxUint32) + 1, nil},
+
+		{"sntp time", DTypeSNTP, time.Unix(0, 0), []byte{0x83, 0xaa, 0x7e, 0x80}},
+		{"sntp uint32", DTypeSNTP, uint32(5), []byte{0, 0, 0, 5}},
+		{"sntp int", DTypeSNTP, 5, []byte{0, 0, 0, 5}},
+		{"sntp negative", DTypeSNTP, -5, nil},
+
+		{"ifid bytes", DTypeIfID, []byte{1, 2, 3, 4, 5, 6, 7, 8}, []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"ifid eui64", DTypeIfID, net.HardwareAddr{1, 2, 3, 4, 5, 6, 7, 8}, []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"ifid uint64", DTypeIfID, uint64(0x0102030405060708), []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"ifid short", DTypeIfID, []byte{1, 2}, nil},
+
+		{"ip6", DTypeIP6, net.ParseIP("2001:db8::1"), []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}},
+		{"ip6 netip", DTypeIP6, netip.MustParseAddr("2001:db8::1"), []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}},
+		{"ip6 invalid", DTypeIP6, netip.Addr{}, nil},
+
+		{"byte", DTypeByte, 7, []byte{7}},
+		{"byte uint8", DTypeByte, uint8(255), []byte{255}},
+		{"byte overflow", DTypeByte, 256, nil},
+
+		{"eth mac", DTypeEth, mac, []byte(mac)},
+		{"eth bytes", DTypeEth, []byte(mac), []byte(mac)},
+		{"eth short", DTypeEth, []byte{1, 2}, nil},
+
+		{"short", DTypeShort, 0x0102, []byte{1, 2}},
+		{"short uint16", DTypeShort, uint16(0xffff), []byte{0xff, 0xff}},
+		{"short overflow", DTypeShort, 0x10000, nil},
+
+		{"vsa bytes", DTypeVSA, []byte{0, 0, 0, 9, 1, 3, 'a'}, []byte{0, 0, 0, 9, 1, 3, 'a'}},
+		{"vsa string", DTypeVSA, "ab", []byte("ab")},
+		{"vsa int", DTypeVSA, 1, nil},
+
+		{"tlv", DTypeTLV, []TLV{{Type: 1, Data: []byte("ab")}}, []byte{1, 4, 'a', 'b'}},
+		{"tlv bytes", DTypeTLV, []byte{1, 3, 'a'}, []byte{1, 3, 'a'}},
+		{"tlv int", DTypeTLV, 1, nil},
+
+		{"unknown dtype", AttrDType(-1), []byte{1}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := attrConv(tt.dt, tt.v)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("attrConv = %x, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("attrConv: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("attrConv = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+// every declared data type can be built with AddAttr
+func TestAttrConvAddAttr(t *testing.T) {
+	vals := map[AttrDType]interface{}{
+		DTypeRaw:    []byte{1},
+		DTypeString: "a",
+		DTypeIP4:    net.IPv4(192, 0, 2, 1),
+		DTypeIP4Pfx: netip.MustParsePrefix("192.0.2.0/24"),
+		DTypeInt:    1,
+		DTypeInt64:  1,
+		DTypeDate:   time.Unix(1, 0),
+		DTypeIfID:   uint64(1),
+		DTypeIP6:    net.ParseIP("2001:db8::1"),
+		DTypeIP6Pfx: netip.MustParsePrefix("2001:db8::/32"),
+		DTypeByte:   1,
+		DTypeEth:    net.HardwareAddr{0, 1, 2, 3, 4, 5},
+		DTypeShort:  1,
+		DTypeSInt:   -1,
+		DTypeVSA:    []byte{1},
+		DTypeSNTP:   time.Unix(1, 0),
+		DTypeTLV:    []TLV{{Type: 1, Data: []byte{1}}},
+	}
+	p, err := NewPacket(AccessRequest, []byte("s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for dt := DTypeRaw; dt <= DTypeTLV; dt++ {
+		v, ok := vals[dt]
+		if !ok {
+			t.Fatalf("no value for dtype %d", dt)
+		}
+		vt := VendorType(200 + dt)
+		if err := AddVSA("Test-Conv-"+string(rune('A'+dt)), 65000, vt, dt); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.AddAttr(AttrVSA, 65000, vt, 0, v); err != nil {
+			t.Errorf("dtype %d: %v", dt, err)
+		}
+	}
+}
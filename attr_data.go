@@ -36,13 +36,16 @@ const (
 	DTypeShort                   // uint16
 	DTypeSInt                    // signed int
 	DTypeVSA                     // VSA
+	DTypeSNTP                    // 32 bit seconds since 1900 (SNTP, RFC 4330)
+	DTypeTLV                     // nested TLVs (RFC 6929)
 )
 
 type AttrType byte   // Attr type
 type VendorID uint32 // Vendor ID for VSA
-type VendorType byte // Vendor type for VSA
 
-const AttrVSA AttrType = 26
+// Vendor type for VSA, extended type for extended attrs. It is uint16 (was
+// byte) to hold 2 and 4 octet vendor types, which are up to 0xffff.
+type VendorType uint16
 
 type AttrData struct {
 	name   string
@@ -52,17 +55,28 @@ type AttrData struct {
 	dtype  AttrDType
 	enc    AttrEnc
 	tagged bool
+	concat bool   // long value is split across consecutive attrs
+	secret bool   // value is masked in output, see SetAttrSecret
+	path   string // dotted path of nested TLV, see AddTLV
+	size   int    // fixed value len, 0 - by data type
+	max    int    // max occurrences in packet, 0 - unlimited
 }
 
 type attrStore struct {
 	sync.RWMutex // just in case RW
 	byName       map[string]*AttrData
 	byAttr       map[uint64]*AttrData
+	vendors      map[VendorID]*vendorFmt
+	byPath       map[string]*AttrData // nested TLVs
+	values       map[uint64]*attrValues
 }
 
 var attrDict = &attrStore{
-	byName: make(map[string]*AttrData),
-	byAttr: make(map[uint64]*AttrData),
+	byName:  make(map[string]*AttrData),
+	byAttr:  make(map[uint64]*AttrData),
+	vendors: make(map[VendorID]*vendorFmt),
+	byPath:  make(map[string]*AttrData),
+	values:  make(map[uint64]*attrValues),
 }
 
 func (ad *AttrData) IsTagged() bool {
@@ -72,6 +86,21 @@ func (ad *AttrData) IsTagged() bool {
 	return ad.tagged
 }
 
+func (ad *AttrData) IsConcat() bool {
+	if ad == nil {
+		return false
+	}
+	return ad.concat
+}
+
+// Encrypted or flagged secret attr, value is masked in Packet.String
+func (ad *AttrData) IsSecret() bool {
+	if ad == nil {
+		return false
+	}
+	return ad.secret || ad.enc != AttrEncNone
+}
+
 func (ad *AttrData) GetEnc() AttrEnc {
 	if ad == nil {
 		return AttrEncNone
@@ -86,6 +115,22 @@ func (ad *AttrData) GetName() string {
 	return ad.name
 }
 
+// Fixed value len, 0 - by data type, see SetAttrLimits
+func (ad *AttrData) GetSize() int {
+	if ad == nil {
+		return 0
+	}
+	return ad.size
+}
+
+// Max occurrences in packet, 0 - unlimited, see SetAttrLimits
+func (ad *AttrData) GetMaxCount() int {
+	if ad == nil {
+		return 0
+	}
+	return ad.max
+}
+
 func (ad *AttrData) GetDataType() AttrDType {
 	if ad == nil {
 		return DTypeRaw
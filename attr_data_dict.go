@@ -6,10 +6,10 @@ import (
 )
 
 func attrKey(atype AttrType, vid VendorID, vtype VendorType) uint64 {
-	if atype != AttrVSA {
+	if atype != AttrVSA && !isExtended(atype) {
 		return uint64(atype)
 	}
-	return (uint64(vid) << 16) | (uint64(vtype) << 8) | uint64(atype)
+	return (uint64(vid) << 24) | (uint64(vtype) << 8) | uint64(atype)
 }
 
 func nameKey(name string) string {
@@ -18,18 +18,8 @@ func nameKey(name string) string {
 
 // Put attrs in dictionary
 
-func AddAttrFull(name string, atype AttrType, vid VendorID, vtype VendorType, dtype AttrDType, enc AttrEnc, tagged bool) (err error) {
-	aKey := attrKey(atype, vid, vtype)
-	nKey := nameKey(name)
-	attrDict.Lock()
-	defer attrDict.Unlock()
-	_, okName := attrDict.byName[nKey]
-	_, okAttr := attrDict.byAttr[aKey]
-	if okName || okAttr {
-		err = errors.New("Attribute exists: " + name)
-		return
-	}
-	attr := &AttrData{
+func AddAttrFull(name string, atype AttrType, vid VendorID, vtype VendorType, dtype AttrDType, enc AttrEnc, tagged bool) error {
+	return addAttrData(&AttrData{
 		name:   name,
 		atype:  atype,
 		vid:    vid,
@@ -37,6 +27,19 @@ func AddAttrFull(name string, atype AttrType, vid VendorID, vtype VendorType, dt
 		dtype:  dtype,
 		enc:    enc,
 		tagged: tagged,
+	})
+}
+
+func addAttrData(attr *AttrData) (err error) {
+	aKey := attrKey(attr.atype, attr.vid, attr.vtype)
+	nKey := nameKey(attr.name)
+	attrDict.Lock()
+	defer attrDict.Unlock()
+	_, okName := attrDict.byName[nKey]
+	_, okAttr := attrDict.byAttr[aKey]
+	if okName || okAttr {
+		err = errors.New("Attribute exists: " + attr.name)
+		return
 	}
 	attrDict.byName[nKey] = attr
 	attrDict.byAttr[aKey] = attr
@@ -82,6 +85,20 @@ func MustAddAttrEncTag(name string, atype AttrType, dtype AttrDType, enc AttrEnc
 	MustAddAttrFull(name, atype, 0, 0, dtype, enc, true)
 }
 
+// Concat attr value longer than one attr is split on serialize and joined on parse
+func AddAttrConcat(name string, atype AttrType, dtype AttrDType) error {
+	if atype == AttrVSA {
+		return errors.New("Concat VSA not supported: " + name)
+	}
+	return addAttrData(&AttrData{name: name, atype: atype, dtype: dtype, concat: true})
+}
+
+func MustAddAttrConcat(name string, atype AttrType, dtype AttrDType) {
+	if err := AddAttrConcat(name, atype, dtype); err != nil {
+		panic(err)
+	}
+}
+
 func AddVSA(name string, vid VendorID, vtype VendorType, dtype AttrDType) error {
 	return AddAttrFull(name, AttrVSA, vid, vtype, dtype, AttrEncNone, false)
 }
@@ -114,6 +131,43 @@ func MustAddVSAEncTag(name string, vid VendorID, vtype VendorType, dtype AttrDTy
 	MustAddAttrFull(name, AttrVSA, vid, vtype, dtype, enc, true)
 }
 
+// attr from dictionary, builtin standard attr is put in dictionary first,
+// nil if not found, dictionary must be locked
+func dictAttr(name string) *AttrData {
+	nKey := nameKey(name)
+	if ad, ok := attrDict.byName[nKey]; ok {
+		return ad
+	}
+	std := rfcAttrsByName[nKey]
+	if std == nil {
+		return nil
+	}
+	ad := *std
+	attrDict.byName[nKey] = &ad
+	attrDict.byAttr[attrKey(ad.atype, ad.vid, ad.vtype)] = &ad
+	return &ad
+}
+
+// Flag attr as secret, its value is masked in Packet.String unless
+// Packet.SetShowSecrets is on. Encrypted attrs are always secret.
+// Builtin standard attr is put in dictionary first.
+func SetAttrSecret(name string, secret bool) error {
+	attrDict.Lock()
+	defer attrDict.Unlock()
+	ad := dictAttr(name)
+	if ad == nil {
+		return errors.New("Attribute not found: " + name)
+	}
+	ad.secret = secret
+	return nil
+}
+
+func MustSetAttrSecret(name string, secret bool) {
+	if err := SetAttrSecret(name, secret); err != nil {
+		panic(err)
+	}
+}
+
 // Get attrs from dictionary
 
 func GetAttrByName(name string) *AttrData {
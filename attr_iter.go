@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package radius
+
+import "iter"
+
+// Attrs in packet order, only attrs of given types if any
+func (p *Packet) Attrs(types ...AttrType) iter.Seq[*Attr] {
+	return func(yield func(*Attr) bool) {
+		if p == nil {
+			return
+		}
+		var am attrMap
+		for _, at := range types {
+			am.set(at)
+		}
+		for _, a := range p.attrList() {
+			if len(types) != 0 && !am.has(a.atype) {
+				continue
+			}
+			if !yield(a) {
+				return
+			}
+		}
+	}
+}
+
+// VSAs of vendor in packet order
+func (p *Packet) VSAs(vid VendorID) iter.Seq[*Attr] {
+	return func(yield func(*Attr) bool) {
+		if !p.HasVendor(vid) {
+			return
+		}
+		for _, a := range p.attrList() {
+			if a.IsVSA() && a.vid == vid && !yield(a) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package radius
+
+// Presence bitmap for attr types
+type attrMap [4]uint64
+
+// Set of VSA vendors
+type vendorSet map[VendorID]struct{}
+
+func (am *attrMap) set(at AttrType) {
+	am[at>>6] |= 1 << (at & 63)
+}
+
+func (am *attrMap) has(at AttrType) bool {
+	return am[at>>6]&(1<<(at&63)) != 0
+}
+
+// append attr to packet and update presence info
+func (p *Packet) appendAttr(attr *Attr) {
+	p.load()
+	p.attrs = append(p.attrs, attr)
+	p.amap.set(attr.atype)
+	if !attr.IsVSA() {
+		return
+	}
+	if _, ok := p.vset[attr.vid]; ok {
+		return
+	}
+	if p.vset == nil {
+		p.vset = make(vendorSet)
+	}
+	p.vset[attr.vid] = struct{}{}
+	for _, v := range p.vids {
+		if v == attr.vid {
+			return
+		}
+	}
+	p.vids = append(p.vids, attr.vid)
+}
+
+// Check attr presence without scanning attrs
+func (p *Packet) HasAttr(at AttrType) bool {
+	if p == nil {
+		return false
+	}
+	return p.amap.has(at)
+}
+
+// Check presence of any VSA from vendor
+func (p *Packet) HasVendor(vid VendorID) bool {
+	if p == nil {
+		return false
+	}
+	p.load()
+	_, ok := p.vset[vid]
+	return ok
+}
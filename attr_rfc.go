@@ -0,0 +1,317 @@
+package radius
+
+// Standard attr types (RFC 2865, 2866, 2868, 2869, 3162, 3576, 4072, 4818, 6911)
+const (
+	AttrUserName               AttrType = 1
+	AttrUserPassword           AttrType = 2
+	AttrCHAPPassword           AttrType = 3
+	AttrNASIPAddress           AttrType = 4
+	AttrNASPort                AttrType = 5
+	AttrServiceType            AttrType = 6
+	AttrFramedProtocol         AttrType = 7
+	AttrFramedIPAddress        AttrType = 8
+	AttrFramedIPNetmask        AttrType = 9
+	AttrFramedRouting          AttrType = 10
+	AttrFilterID               AttrType = 11
+	AttrFramedMTU              AttrType = 12
+	AttrFramedCompression      AttrType = 13
+	AttrLoginIPHost            AttrType = 14
+	AttrLoginService           AttrType = 15
+	AttrLoginTCPPort           AttrType = 16
+	AttrReplyMessage           AttrType = 18
+	AttrCallbackNumber         AttrType = 19
+	AttrCallbackID             AttrType = 20
+	AttrFramedRoute            AttrType = 22
+	AttrFramedIPXNetwork       AttrType = 23
+	AttrState                  AttrType = 24
+	AttrClass                  AttrType = 25
+	AttrVSA                    AttrType = 26
+	AttrSessionTimeout         AttrType = 27
+	AttrIdleTimeout            AttrType = 28
+	AttrTerminationAction      AttrType = 29
+	AttrCalledStationID        AttrType = 30
+	AttrCallingStationID       AttrType = 31
+	AttrNASIdentifier          AttrType = 32
+	AttrProxyState             AttrType = 33
+	AttrLoginLATService        AttrType = 34
+	AttrLoginLATNode           AttrType = 35
+	AttrLoginLATGroup          AttrType = 36
+	AttrFramedAppleTalkLink    AttrType = 37
+	AttrFramedAppleTalkNetwork AttrType = 38
+	AttrFramedAppleTalkZone    AttrType = 39
+	AttrAcctStatusType         AttrType = 40
+	AttrAcctDelayTime          AttrType = 41
+	AttrAcctInputOctets        AttrType = 42
+	AttrAcctOutputOctets       AttrType = 43
+	AttrAcctSessionID          AttrType = 44
+	AttrAcctAuthentic          AttrType = 45
+	AttrAcctSessionTime        AttrType = 46
+	AttrAcctInputPackets       AttrType = 47
+	AttrAcctOutputPackets      AttrType = 48
+	AttrAcctTerminateCause     AttrType = 49
+	AttrAcctMultiSessionID     AttrType = 50
+	AttrAcctLinkCount          AttrType = 51
+	AttrAcctInputGigawords     AttrType = 52
+	AttrAcctOutputGigawords    AttrType = 53
+	AttrEventTimestamp         AttrType = 55
+	AttrCHAPChallenge          AttrType = 60
+	AttrNASPortType            AttrType = 61
+	AttrPortLimit              AttrType = 62
+	AttrLoginLATPort           AttrType = 63
+	AttrTunnelType             AttrType = 64
+	AttrTunnelMediumType       AttrType = 65
+	AttrTunnelClientEndpoint   AttrType = 66
+	AttrTunnelServerEndpoint   AttrType = 67
+	AttrAcctTunnelConnection   AttrType = 68
+	AttrTunnelPassword         AttrType = 69
+	AttrARAPPassword           AttrType = 70
+	AttrARAPFeatures           AttrType = 71
+	AttrARAPZoneAccess         AttrType = 72
+	AttrARAPSecurity           AttrType = 73
+	AttrARAPSecurityData       AttrType = 74
+	AttrPasswordRetry          AttrType = 75
+	AttrPrompt                 AttrType = 76
+	AttrConnectInfo            AttrType = 77
+	AttrConfigurationToken     AttrType = 78
+	AttrEAPMessage             AttrType = 79
+	AttrMessageAuthenticator   AttrType = 80
+	AttrTunnelPrivateGroupID   AttrType = 81
+	AttrTunnelAssignmentID     AttrType = 82
+	AttrTunnelPreference       AttrType = 83
+	AttrARAPChallengeResponse  AttrType = 84
+	AttrAcctInterimInterval    AttrType = 85
+	AttrAcctTunnelPacketsLost  AttrType = 86
+	AttrNASPortID              AttrType = 87
+	AttrFramedPool             AttrType = 88
+	AttrCUI                    AttrType = 89
+	AttrTunnelClientAuthID     AttrType = 90
+	AttrTunnelServerAuthID     AttrType = 91
+	AttrNASFilterRule          AttrType = 92
+	AttrOriginatingLineInfo    AttrType = 94
+	AttrNASIPv6Address         AttrType = 95
+	AttrFramedInterfaceID      AttrType = 96
+	AttrFramedIPv6Prefix       AttrType = 97
+	AttrLoginIPv6Host          AttrType = 98
+	AttrFramedIPv6Route        AttrType = 99
+	AttrFramedIPv6Pool         AttrType = 100
+	AttrErrorCause             AttrType = 101
+	AttrEAPKeyName             AttrType = 102
+	AttrDigestResponse         AttrType = 103
+	AttrDigestRealm            AttrType = 104
+	AttrDigestNonce            AttrType = 105
+	AttrDigestResponseAuth     AttrType = 106
+	AttrDigestNextnonce        AttrType = 107
+	AttrDigestMethod           AttrType = 108
+	AttrDigestURI              AttrType = 109
+	AttrDigestQop              AttrType = 110
+	AttrDigestAlgorithm        AttrType = 111
+	AttrDigestEntityBodyHash   AttrType = 112
+	AttrDigestCNonce           AttrType = 113
+	AttrDigestNonceCount       AttrType = 114
+	AttrDigestUsername         AttrType = 115
+	AttrDigestOpaque           AttrType = 116
+	AttrDigestAuthParam        AttrType = 117
+	AttrDigestAKAAuts          AttrType = 118
+	AttrDigestDomain           AttrType = 119
+	AttrDigestStale            AttrType = 120
+	AttrDigestHA1              AttrType = 121
+	AttrSIPAOR                 AttrType = 122
+	AttrDelegatedIPv6Prefix    AttrType = 123
+	AttrFramedIPv6Address      AttrType = 168
+	AttrDNSServerIPv6Address   AttrType = 169
+	AttrRouteIPv6Information   AttrType = 170
+	AttrDelegatedIPv6PfxPool   AttrType = 171
+	AttrStatefulIPv6AddrPool   AttrType = 172
+)
+
+func rfcAttr(name string, atype AttrType, dtype AttrDType) *AttrData {
+	return &AttrData{name: name, atype: atype, dtype: dtype}
+}
+
+func rfcAttrEnc(name string, atype AttrType, dtype AttrDType, enc AttrEnc, tagged bool) *AttrData {
+	return &AttrData{name: name, atype: atype, dtype: dtype, enc: enc, tagged: tagged}
+}
+
+func rfcAttrSecret(name string, atype AttrType, dtype AttrDType) *AttrData {
+	return &AttrData{name: name, atype: atype, dtype: dtype, secret: true}
+}
+
+func rfcAttrConcat(name string, atype AttrType, dtype AttrDType) *AttrData {
+	return &AttrData{name: name, atype: atype, dtype: dtype, concat: true}
+}
+
+// Builtin data for standard attrs, used when attr is not in dictionary
+var rfcAttrs = map[AttrType]*AttrData{
+	AttrUserName:               rfcAttr("User-Name", AttrUserName, DTypeString),
+	AttrUserPassword:           rfcAttrEnc("User-Password", AttrUserPassword, DTypeString, AttrEncUsr, false),
+	AttrCHAPPassword:           rfcAttrSecret("CHAP-Password", AttrCHAPPassword, DTypeRaw),
+	AttrNASIPAddress:           rfcAttr("NAS-IP-Address", AttrNASIPAddress, DTypeIP4),
+	AttrNASPort:                rfcAttr("NAS-Port", AttrNASPort, DTypeInt),
+	AttrServiceType:            rfcAttr("Service-Type", AttrServiceType, DTypeInt),
+	AttrFramedProtocol:         rfcAttr("Framed-Protocol", AttrFramedProtocol, DTypeInt),
+	AttrFramedIPAddress:        rfcAttr("Framed-IP-Address", AttrFramedIPAddress, DTypeIP4),
+	AttrFramedIPNetmask:        rfcAttr("Framed-IP-Netmask", AttrFramedIPNetmask, DTypeIP4),
+	AttrFramedRouting:          rfcAttr("Framed-Routing", AttrFramedRouting, DTypeInt),
+	AttrFilterID:               rfcAttr("Filter-Id", AttrFilterID, DTypeString),
+	AttrFramedMTU:              rfcAttr("Framed-MTU", AttrFramedMTU, DTypeInt),
+	AttrFramedCompression:      rfcAttr("Framed-Compression", AttrFramedCompression, DTypeInt),
+	AttrLoginIPHost:            rfcAttr("Login-IP-Host", AttrLoginIPHost, DTypeIP4),
+	AttrLoginService:           rfcAttr("Login-Service", AttrLoginService, DTypeInt),
+	AttrLoginTCPPort:           rfcAttr("Login-TCP-Port", AttrLoginTCPPort, DTypeInt),
+	AttrReplyMessage:           rfcAttr("Reply-Message", AttrReplyMessage, DTypeString),
+	AttrCallbackNumber:         rfcAttr("Callback-Number", AttrCallbackNumber, DTypeString),
+	AttrCallbackID:             rfcAttr("Callback-Id", AttrCallbackID, DTypeString),
+	AttrFramedRoute:            rfcAttr("Framed-Route", AttrFramedRoute, DTypeString),
+	AttrFramedIPXNetwork:       rfcAttr("Framed-IPX-Network", AttrFramedIPXNetwork, DTypeIP4),
+	AttrState:                  rfcAttr("State", AttrState, DTypeRaw),
+	AttrClass:                  rfcAttr("Class", AttrClass, DTypeRaw),
+	AttrSessionTimeout:         rfcAttr("Session-Timeout", AttrSessionTimeout, DTypeInt),
+	AttrIdleTimeout:            rfcAttr("Idle-Timeout", AttrIdleTimeout, DTypeInt),
+	AttrTerminationAction:      rfcAttr("Termination-Action", AttrTerminationAction, DTypeInt),
+	AttrCalledStationID:        rfcAttr("Called-Station-Id", AttrCalledStationID, DTypeString),
+	AttrCallingStationID:       rfcAttr("Calling-Station-Id", AttrCallingStationID, DTypeString),
+	AttrNASIdentifier:          rfcAttr("NAS-Identifier", AttrNASIdentifier, DTypeString),
+	AttrProxyState:             rfcAttr("Proxy-State", AttrProxyState, DTypeRaw),
+	AttrLoginLATService:        rfcAttr("Login-LAT-Service", AttrLoginLATService, DTypeString),
+	AttrLoginLATNode:           rfcAttr("Login-LAT-Node", AttrLoginLATNode, DTypeString),
+	AttrLoginLATGroup:          rfcAttr("Login-LAT-Group", AttrLoginLATGroup, DTypeRaw),
+	AttrFramedAppleTalkLink:    rfcAttr("Framed-AppleTalk-Link", AttrFramedAppleTalkLink, DTypeInt),
+	AttrFramedAppleTalkNetwork: rfcAttr("Framed-AppleTalk-Network", AttrFramedAppleTalkNetwork, DTypeInt),
+	AttrFramedAppleTalkZone:    rfcAttr("Framed-AppleTalk-Zone", AttrFramedAppleTalkZone, DTypeString),
+	AttrAcctStatusType:         rfcAttr("Acct-Status-Type", AttrAcctStatusType, DTypeInt),
+	AttrAcctDelayTime:          rfcAttr("Acct-Delay-Time", AttrAcctDelayTime, DTypeInt),
+	AttrAcctInputOctets:        rfcAttr("Acct-Input-Octets", AttrAcctInputOctets, DTypeInt),
+	AttrAcctOutputOctets:       rfcAttr("Acct-Output-Octets", AttrAcctOutputOctets, DTypeInt),
+	AttrAcctSessionID:          rfcAttr("Acct-Session-Id", AttrAcctSessionID, DTypeString),
+	AttrAcctAuthentic:          rfcAttr("Acct-Authentic", AttrAcctAuthentic, DTypeInt),
+	AttrAcctSessionTime:        rfcAttr("Acct-Session-Time", AttrAcctSessionTime, DTypeInt),
+	AttrAcctInputPackets:       rfcAttr("Acct-Input-Packets", AttrAcctInputPackets, DTypeInt),
+	AttrAcctOutputPackets:      rfcAttr("Acct-Output-Packets", AttrAcctOutputPackets, DTypeInt),
+	AttrAcctTerminateCause:     rfcAttr("Acct-Terminate-Cause", AttrAcctTerminateCause, DTypeInt),
+	AttrAcctMultiSessionID:     rfcAttr("Acct-Multi-Session-Id", AttrAcctMultiSessionID, DTypeString),
+	AttrAcctLinkCount:          rfcAttr("Acct-Link-Count", AttrAcctLinkCount, DTypeInt),
+	AttrAcctInputGigawords:     rfcAttr("Acct-Input-Gigawords", AttrAcctInputGigawords, DTypeInt),
+	AttrAcctOutputGigawords:    rfcAttr("Acct-Output-Gigawords", AttrAcctOutputGigawords, DTypeInt),
+	AttrEventTimestamp:         rfcAttr("Event-Timestamp", AttrEventTimestamp, DTypeDate),
+	AttrCHAPChallenge:          rfcAttr("CHAP-Challenge", AttrCHAPChallenge, DTypeRaw),
+	AttrNASPortType:            rfcAttr("NAS-Port-Type", AttrNASPortType, DTypeInt),
+	AttrPortLimit:              rfcAttr("Port-Limit", AttrPortLimit, DTypeInt),
+	AttrLoginLATPort:           rfcAttr("Login-LAT-Port", AttrLoginLATPort, DTypeString),
+	AttrTunnelType:             rfcAttrEnc("Tunnel-Type", AttrTunnelType, DTypeInt, AttrEncNone, true),
+	AttrTunnelMediumType:       rfcAttrEnc("Tunnel-Medium-Type", AttrTunnelMediumType, DTypeInt, AttrEncNone, true),
+	AttrTunnelClientEndpoint:   rfcAttrEnc("Tunnel-Client-Endpoint", AttrTunnelClientEndpoint, DTypeString, AttrEncNone, true),
+	AttrTunnelServerEndpoint:   rfcAttrEnc("Tunnel-Server-Endpoint", AttrTunnelServerEndpoint, DTypeString, AttrEncNone, true),
+	AttrAcctTunnelConnection:   rfcAttr("Acct-Tunnel-Connection", AttrAcctTunnelConnection, DTypeString),
+	AttrTunnelPassword:         rfcAttrEnc("Tunnel-Password", AttrTunnelPassword, DTypeString, AttrEncTun, true),
+	AttrARAPPassword:           rfcAttr("ARAP-Password", AttrARAPPassword, DTypeRaw),
+	AttrARAPFeatures:           rfcAttr("ARAP-Features", AttrARAPFeatures, DTypeRaw),
+	AttrARAPZoneAccess:         rfcAttr("ARAP-Zone-Access", AttrARAPZoneAccess, DTypeInt),
+	AttrARAPSecurity:           rfcAttr("ARAP-Security", AttrARAPSecurity, DTypeInt),
+	AttrARAPSecurityData:       rfcAttr("ARAP-Security-Data", AttrARAPSecurityData, DTypeString),
+	AttrPasswordRetry:          rfcAttr("Password-Retry", AttrPasswordRetry, DTypeInt),
+	AttrPrompt:                 rfcAttr("Prompt", AttrPrompt, DTypeInt),
+	AttrConnectInfo:            rfcAttr("Connect-Info", AttrConnectInfo, DTypeString),
+	AttrConfigurationToken:     rfcAttr("Configuration-Token", AttrConfigurationToken, DTypeString),
+	AttrEAPMessage:             rfcAttrConcat("EAP-Message", AttrEAPMessage, DTypeRaw),
+	AttrMessageAuthenticator:   rfcAttr("Message-Authenticator", AttrMessageAuthenticator, DTypeRaw),
+	AttrTunnelPrivateGroupID:   rfcAttrEnc("Tunnel-Private-Group-Id", AttrTunnelPrivateGroupID, DTypeString, AttrEncNone, true),
+	AttrTunnelAssignmentID:     rfcAttrEnc("Tunnel-Assignment-Id", AttrTunnelAssignmentID, DTypeString, AttrEncNone, true),
+	AttrTunnelPreference:       rfcAttrEnc("Tunnel-Preference", AttrTunnelPreference, DTypeInt, AttrEncNone, true),
+	AttrARAPChallengeResponse:  rfcAttr("ARAP-Challenge-Response", AttrARAPChallengeResponse, DTypeRaw),
+	AttrAcctInterimInterval:    rfcAttr("Acct-Interim-Interval", AttrAcctInterimInterval, DTypeInt),
+	AttrAcctTunnelPacketsLost:  rfcAttr("Acct-Tunnel-Packets-Lost", AttrAcctTunnelPacketsLost, DTypeInt),
+	AttrNASPortID:              rfcAttr("NAS-Port-Id", AttrNASPortID, DTypeString),
+	AttrFramedPool:             rfcAttr("Framed-Pool", AttrFramedPool, DTypeString),
+	AttrCUI:                    rfcAttr("Chargeable-User-Identity", AttrCUI, DTypeRaw),
+	AttrTunnelClientAuthID:     rfcAttrEnc("Tunnel-Client-Auth-Id", AttrTunnelClientAuthID, DTypeString, AttrEncNone, true),
+	AttrTunnelServerAuthID:     rfcAttrEnc("Tunnel-Server-Auth-Id", AttrTunnelServerAuthID, DTypeString, AttrEncNone, true),
+	AttrNASFilterRule:          rfcAttr("NAS-Filter-Rule", AttrNASFilterRule, DTypeString),
+	AttrOriginatingLineInfo:    rfcAttr("Originating-Line-Info", AttrOriginatingLineInfo, DTypeRaw),
+	AttrNASIPv6Address:         rfcAttr("NAS-IPv6-Address", AttrNASIPv6Address, DTypeIP6),
+	AttrFramedInterfaceID:      rfcAttr("Framed-Interface-Id", AttrFramedInterfaceID, DTypeIfID),
+	AttrFramedIPv6Prefix:       rfcAttr("Framed-IPv6-Prefix", AttrFramedIPv6Prefix, DTypeIP6Pfx),
+	AttrLoginIPv6Host:          rfcAttr("Login-IPv6-Host", AttrLoginIPv6Host, DTypeIP6),
+	AttrFramedIPv6Route:        rfcAttr("Framed-IPv6-Route", AttrFramedIPv6Route, DTypeString),
+	AttrFramedIPv6Pool:         rfcAttr("Framed-IPv6-Pool", AttrFramedIPv6Pool, DTypeString),
+	AttrErrorCause:             rfcAttr("Error-Cause", AttrErrorCause, DTypeInt),
+	AttrEAPKeyName:             rfcAttr("EAP-Key-Name", AttrEAPKeyName, DTypeRaw),
+	AttrDigestResponse:         rfcAttr("Digest-Response", AttrDigestResponse, DTypeString),
+	AttrDigestRealm:            rfcAttr("Digest-Realm", AttrDigestRealm, DTypeString),
+	AttrDigestNonce:            rfcAttr("Digest-Nonce", AttrDigestNonce, DTypeString),
+	AttrDigestResponseAuth:     rfcAttr("Digest-Response-Auth", AttrDigestResponseAuth, DTypeString),
+	AttrDigestNextnonce:        rfcAttr("Digest-Nextnonce", AttrDigestNextnonce, DTypeString),
+	AttrDigestMethod:           rfcAttr("Digest-Method", AttrDigestMethod, DTypeString),
+	AttrDigestURI:              rfcAttr("Digest-URI", AttrDigestURI, DTypeString),
+	AttrDigestQop:              rfcAttr("Digest-Qop", AttrDigestQop, DTypeString),
+	AttrDigestAlgorithm:        rfcAttr("Digest-Algorithm", AttrDigestAlgorithm, DTypeString),
+	AttrDigestEntityBodyHash:   rfcAttr("Digest-Entity-Body-Hash", AttrDigestEntityBodyHash, DTypeString),
+	AttrDigestCNonce:           rfcAttr("Digest-CNonce", AttrDigestCNonce, DTypeString),
+	AttrDigestNonceCount:       rfcAttr("Digest-Nonce-Count", AttrDigestNonceCount, DTypeString),
+	AttrDigestUsername:         rfcAttr("Digest-Username", AttrDigestUsername, DTypeString),
+	AttrDigestOpaque:           rfcAttr("Digest-Opaque", AttrDigestOpaque, DTypeString),
+	AttrDigestAuthParam:        rfcAttr("Digest-Auth-Param", AttrDigestAuthParam, DTypeString),
+	AttrDigestAKAAuts:          rfcAttr("Digest-AKA-Auts", AttrDigestAKAAuts, DTypeString),
+	AttrDigestDomain:           rfcAttr("Digest-Domain", AttrDigestDomain, DTypeString),
+	AttrDigestStale:            rfcAttr("Digest-Stale", AttrDigestStale, DTypeString),
+	AttrDigestHA1:              rfcAttrSecret("Digest-HA1", AttrDigestHA1, DTypeString),
+	AttrSIPAOR:                 rfcAttr("SIP-AOR", AttrSIPAOR, DTypeString),
+	AttrDelegatedIPv6Prefix:    rfcAttr("Delegated-IPv6-Prefix", AttrDelegatedIPv6Prefix, DTypeIP6Pfx),
+	AttrFramedIPv6Address:      rfcAttr("Framed-IPv6-Address", AttrFramedIPv6Address, DTypeIP6),
+	AttrDNSServerIPv6Address:   rfcAttr("DNS-Server-IPv6-Address", AttrDNSServerIPv6Address, DTypeIP6),
+	AttrRouteIPv6Information:   rfcAttr("Route-IPv6-Information", AttrRouteIPv6Information, DTypeIP6Pfx),
+	AttrDelegatedIPv6PfxPool:   rfcAttr("Delegated-IPv6-Prefix-Pool", AttrDelegatedIPv6PfxPool, DTypeString),
+	AttrStatefulIPv6AddrPool:   rfcAttr("Stateful-IPv6-Address-Pool", AttrStatefulIPv6AddrPool, DTypeString),
+}
+
+// Attr data from dictionary or builtin standard attr data
+func stdAttr(at AttrType) *AttrData {
+	if ad := GetAttrByAttr(at); ad != nil {
+		return ad
+	}
+	return rfcAttrs[at]
+}
+
+// VSA data from dictionary or builtin VSA data
+func stdVSA(vid VendorID, vtype VendorType) *AttrData {
+	if ad := GetVSAByAttr(vid, vtype); ad != nil {
+		return ad
+	}
+	return rfcVSAs[attrKey(AttrVSA, vid, vtype)]
+}
+
+// Attr data from dictionary or builtin data of any attr kind, EVS attrs
+// are known from dictionary only
+func stdAttrFull(atype AttrType, vid VendorID, vtype VendorType) *AttrData {
+	switch {
+	case atype == AttrVSA:
+		return stdVSA(vid, vtype)
+	case isExtended(atype) && vid == 0:
+		return stdExtAttr(atype, vtype)
+	case isExtended(atype):
+		return GetEVSByAttr(atype, vid, vtype)
+	}
+	return stdAttr(atype)
+}
+
+// Builtin standard attrs by name key
+var rfcAttrsByName = func() map[string]*AttrData {
+	m := make(map[string]*AttrData, len(rfcAttrs)+len(rfcExtAttrs)+len(rfcVSAs))
+	for _, ad := range rfcAttrs {
+		m[nameKey(ad.name)] = ad
+	}
+	for _, ad := range rfcExtAttrs {
+		m[nameKey(ad.name)] = ad
+	}
+	for _, ad := range rfcVSAs {
+		m[nameKey(ad.name)] = ad
+	}
+	return m
+}()
+
+// Attr data by name from dictionary, then from builtin standard attrs
+func stdAttrByName(name string) *AttrData {
+	if ad := GetAttrByName(name); ad != nil {
+		return ad
+	}
+	return rfcAttrsByName[nameKey(name)]
+}
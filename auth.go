@@ -0,0 +1,326 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"errors"
+)
+
+const authLen = 16 // Authenticator len
+
+// MD5(Code+ID+Length+auth+Attributes+Secret), pkt is whole packet,
+// auth replaces authenticator field
+func calcAuth(pkt, auth, secret []byte) []byte {
+	h := newHash(crypto.MD5)
+	h.Write(pkt[:4])
+	h.Write(auth)
+	h.Write(pkt[MinPLen:])
+	h.Write(secret)
+	return h.Sum(nil)
+}
+
+var zeroAuth [authLen]byte
+
+var (
+	errBadAuth    = errors.New("Invalid authenticator")
+	errBadMsgAuth = errors.New("Invalid Message-Authenticator")
+	errNoMsgAuth  = errors.New("Message-Authenticator missing")
+)
+
+const msgAuthLen = 18 // Message-Authenticator attr len
+
+// Request Authenticator is MD5 over packet with zero authenticator
+func zeroAuthCode(code RadiusCode) bool {
+	switch code {
+	case AccountingRequest, DisconnectRequest, CoARequest:
+		return true
+	}
+	return false
+}
+
+// Request with random authenticator
+func randAuthCode(code RadiusCode) bool {
+	return code == AccessRequest || code == StatusServer
+}
+
+// Message-Authenticator is mandatory for these codes in hardened mode
+// (Blast-RADIUS, CVE-2024-3596)
+func msgAuthCode(code RadiusCode) bool {
+	switch code {
+	case AccessRequest, AccessAccept, AccessReject, AccessChallenge, StatusServer:
+		return true
+	}
+	return false
+}
+
+// Message-Authenticator is emitted first on serialize, wherever it is in
+// attrs, exact mode keeps it in place (see msgAuthInPlace). In hardened
+// mode it is emitted even if packet has none.
+func (p *Packet) emitMsgAuth() bool {
+	if p.HasAttr(AttrMessageAuthenticator) {
+		return !p.msgAuthInPlace()
+	}
+	return p.mauth && msgAuthCode(p.code)
+}
+
+// exact mode keeps single valid Message-Authenticator in place, malformed
+// or repeated one can't be signed there
+func (p *Packet) msgAuthInPlace() bool {
+	if !p.exact || !p.HasAttr(AttrMessageAuthenticator) {
+		return false
+	}
+	n := 0
+	for _, a := range p.attrList() {
+		if a.atype != AttrMessageAuthenticator {
+			continue
+		}
+		if n++; n > 1 || len(a.data) != authLen {
+			return false
+		}
+	}
+	return true
+}
+
+// Response with Authenticator as MD5 over packet, request Authenticator and secret
+func respAuthCode(code RadiusCode) bool {
+	switch code {
+	case AccessAccept, AccessReject, AccessChallenge, AccountingResponse,
+		DisconnectACK, DisconnectNAK, CoAACK, CoANAK:
+		return true
+	}
+	return false
+}
+
+// fill Message-Authenticator and Authenticator field of serialized pkt,
+// packet auth is left intact so for responses it keeps request Authenticator
+func (p *Packet) signAuth(pkt []byte) error {
+	if len(p.secret) == 0 {
+		return nil
+	}
+	resp := respAuthCode(p.code) && len(p.auth) == authLen
+	auth := pkt[4:MinPLen]
+	switch {
+	case resp:
+		auth = p.auth
+	case zeroAuthCode(p.code):
+		auth = zeroAuth[:]
+	}
+	off, err := findMsgAuth(pkt)
+	if err != nil {
+		return err
+	}
+	if off != 0 {
+		copy(pkt[off:off+authLen], calcMsgAuth(pkt, auth, p.secret, off))
+	}
+	switch {
+	case resp:
+		copy(pkt[4:MinPLen], calcAuth(pkt, p.auth, p.secret))
+	case zeroAuthCode(p.code):
+		p.auth = calcAuth(pkt, zeroAuth[:], p.secret)
+		copy(pkt[4:MinPLen], p.auth)
+	}
+	return nil
+}
+
+// Add Message-Authenticator placeholder, value is calculated on serialize.
+// Does nothing if packet already has one.
+func (p *Packet) AddMsgAuth() error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if p.HasAttr(AttrMessageAuthenticator) {
+		return nil
+	}
+	return p.addStd(AttrMessageAuthenticator, 0, make([]byte, authLen))
+}
+
+// Verify Message-Authenticator of parsed request, error if it is missing.
+// For replies use VerifyReply as HMAC covers request Authenticator.
+func (p *Packet) VerifyMsgAuth(secret []byte) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if p.data == nil {
+		return errors.New("Packet not parsed")
+	}
+	if respAuthCode(p.code) {
+		return errors.New("Not a request: " + p.code.String())
+	}
+	pl, err := checkHeader(p.data, MaxLongLen)
+	if err != nil {
+		return err
+	}
+	auth := p.data[4:MinPLen]
+	if zeroAuthCode(p.code) {
+		auth = zeroAuth[:]
+	}
+	return verifyMsgAuth(p.data[:pl], auth, secret, true)
+}
+
+// Generate random Request Authenticator from packet random source,
+// Access-Request and Status-Server get one on serialize if not set.
+// Keep returned value to verify response.
+func (p *Packet) GenAuth() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("Packet empty")
+	}
+	auth := make([]byte, authLen)
+	if err := p.randRead(auth); err != nil {
+		return nil, err
+	}
+	p.auth = auth
+	return auth, nil
+}
+
+// HMAC-MD5 over pkt with auth in authenticator field and zeroed
+// Message-Authenticator value at maOff
+func calcMsgAuth(pkt, auth, secret []byte, maOff int) []byte {
+	h := newHMAC(crypto.MD5, secret)
+	h.Write(pkt[:4])
+	h.Write(auth)
+	h.Write(pkt[MinPLen:maOff])
+	h.Write(zeroAuth[:])
+	h.Write(pkt[maOff+authLen:])
+	return h.Sum(nil)
+}
+
+// offset of Message-Authenticator value in pkt, 0 if absent
+func findMsgAuth(pkt []byte) (int, error) {
+	off := 0
+	rb := rBuf{buf: pkt[MinPLen:], bl: len(pkt) - MinPLen}
+	for rb.getLeft() > 0 {
+		pos := MinPLen + rb.bp
+		at, ad, err := rb.getAttr()
+		if err != nil {
+			return 0, err
+		}
+		if AttrType(at) != AttrMessageAuthenticator {
+			continue
+		}
+		if len(ad) != authLen || off != 0 {
+			return 0, errBadMsgAuth
+		}
+		off = pos + 2
+	}
+	return off, nil
+}
+
+// check Message-Authenticator if present, auth is authenticator used for HMAC
+func verifyMsgAuth(pkt, auth, secret []byte, required bool) error {
+	off, err := findMsgAuth(pkt)
+	if err != nil {
+		return err
+	}
+	if off == 0 {
+		if required {
+			return errNoMsgAuth
+		}
+		return nil
+	}
+	if !hmac.Equal(pkt[off:off+authLen], calcMsgAuth(pkt, auth, secret, off)) {
+		return errBadMsgAuth
+	}
+	return nil
+}
+
+// Verify request authenticators on raw packet without parsing attrs.
+// Accounting, CoA and Disconnect requests are checked against MD5 of packet,
+// Message-Authenticator is checked if present (required for Status-Server).
+func VerifyRequestRaw(buf, secret []byte) error {
+	return verifyRequest(buf, secret, false)
+}
+
+// Same as VerifyRequestRaw, but Access-Request must have Message-Authenticator
+func VerifyRequestRawStrict(buf, secret []byte) error {
+	return verifyRequest(buf, secret, true)
+}
+
+func verifyRequest(buf, secret []byte, strict bool) error {
+	pl, err := checkHeader(buf, MaxLongLen)
+	if err != nil {
+		return err
+	}
+	pkt := buf[:pl]
+	code := RadiusCode(pkt[0])
+	switch {
+	case zeroAuthCode(code):
+		if !hmac.Equal(pkt[4:MinPLen], calcAuth(pkt, zeroAuth[:], secret)) {
+			return errBadAuth
+		}
+		return verifyMsgAuth(pkt, zeroAuth[:], secret, false)
+	case randAuthCode(code):
+		return verifyMsgAuth(pkt, pkt[4:MinPLen], secret, strict || code == StatusServer)
+	}
+	return errors.New("Not a request: " + code.String())
+}
+
+// Verify response authenticators on raw packet, reqAuth is request authenticator
+func VerifyResponseRaw(buf, reqAuth, secret []byte) error {
+	return verifyResponse(buf, reqAuth, secret, false)
+}
+
+// Same as VerifyResponseRaw, but Access-Accept/Reject/Challenge must have
+// Message-Authenticator
+func VerifyResponseRawStrict(buf, reqAuth, secret []byte) error {
+	return verifyResponse(buf, reqAuth, secret, true)
+}
+
+func verifyResponse(buf, reqAuth, secret []byte, strict bool) error {
+	pl, err := checkHeader(buf, MaxLongLen)
+	if err != nil {
+		return err
+	}
+	if len(reqAuth) != authLen {
+		return errors.New("Invalid request authenticator")
+	}
+	pkt := buf[:pl]
+	if !hmac.Equal(pkt[4:MinPLen], calcAuth(pkt, reqAuth, secret)) {
+		return errBadAuth
+	}
+	return verifyMsgAuth(pkt, reqAuth, secret, strict && msgAuthCode(RadiusCode(pkt[0])))
+}
+
+// Verify reply against original request: ID, Response Authenticator and
+// Message-Authenticator if present. Reply must be parsed from wire.
+func (p *Packet) VerifyReply(request *Packet, secret []byte) error {
+	if p == nil || request == nil {
+		return errors.New("Packet empty")
+	}
+	if p.data == nil {
+		return errors.New("Packet not parsed")
+	}
+	if !respAuthCode(p.code) {
+		return errors.New("Not a reply: " + p.code.String())
+	}
+	if p.id != request.id {
+		return errors.New("Reply ID mismatch")
+	}
+	if err := verifyResponse(p.data, request.auth, secret, p.mauth || request.mauth); err != nil {
+		return err
+	}
+	p.SetRequestAuth(cloneBytes(request.auth)) // to decrypt reply attrs
+	return nil
+}
+
+// Verify Request Authenticator of parsed Accounting-Request
+func (p *Packet) VerifyAcctRequest(secret []byte) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if p.code != AccountingRequest {
+		return errors.New("Not an accounting request: " + p.code.String())
+	}
+	return p.VerifyRequest(secret)
+}
+
+// Verify authenticators of parsed request, see VerifyRequestRaw.
+// Covers RFC 5176 CoA-Request and Disconnect-Request.
+func (p *Packet) VerifyRequest(secret []byte) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if p.data == nil {
+		return errors.New("Packet not parsed")
+	}
+	return verifyRequest(p.data, secret, p.mauth)
+}
@@ -0,0 +1,26 @@
+package radius
+
+// Malformed attr skipped in permissive parse mode
+type BadAttr struct {
+	Offset int    // Offset in packet
+	Data   []byte // Wire form, rest of packet if attr len is broken
+	Err    error
+}
+
+// pos is offset of attr after header
+func (p *Packet) addBad(pos int, data []byte, err error) {
+	p.bad = append(p.bad, BadAttr{
+		Offset: MinPLen + pos,
+		Data:   data,
+		Err:    err,
+	})
+}
+
+// Malformed attrs skipped in permissive mode, not written by Serialize
+func (p *Packet) BadAttrs() []BadAttr {
+	if p == nil {
+		return nil
+	}
+	p.load()
+	return p.bad
+}
@@ -0,0 +1,128 @@
+package radius
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// bcrypt ($2a$, $2b$, $2y$) verification, Blowfish is not in standard library
+
+const (
+	bcryptMinCost = 4
+	bcryptMaxCost = 31
+	bcryptSaltLen = 22 // encoded 16 bytes salt
+	bcryptHashLen = 31 // encoded 23 bytes hash
+	bcryptMaxKey  = 72
+)
+
+var bcryptB64 = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").
+	WithPadding(base64.NoPadding)
+
+var errBcrypt = errors.New("Invalid bcrypt hash")
+
+type blowfish struct {
+	p [18]uint32
+	s [4][256]uint32
+}
+
+func (c *blowfish) f(x uint32) uint32 {
+	return ((c.s[0][x>>24] + c.s[1][x>>16&0xff]) ^ c.s[2][x>>8&0xff]) + c.s[3][x&0xff]
+}
+
+func (c *blowfish) encrypt(l, r uint32) (uint32, uint32) {
+	for i := 0; i < 16; i += 2 {
+		l ^= c.p[i]
+		r ^= c.f(l)
+		r ^= c.p[i+1]
+		l ^= c.f(r)
+	}
+	return r ^ c.p[17], l ^ c.p[16]
+}
+
+// next cyclic big endian word of b
+func bfWord(b []byte, j *int) uint32 {
+	var w uint32
+	for i := 0; i < 4; i++ {
+		w = w<<8 | uint32(b[*j])
+		*j = (*j + 1) % len(b)
+	}
+	return w
+}
+
+// key schedule, salt is XORed into blocks if not nil (eksblowfish)
+func (c *blowfish) expand(key, salt []byte) {
+	j := 0
+	for i := range c.p {
+		c.p[i] ^= bfWord(key, &j)
+	}
+	var l, r uint32
+	j = 0
+	next := func() {
+		if salt != nil {
+			l ^= bfWord(salt, &j)
+			r ^= bfWord(salt, &j)
+		}
+		l, r = c.encrypt(l, r)
+	}
+	for i := 0; i < len(c.p); i += 2 {
+		next()
+		c.p[i], c.p[i+1] = l, r
+	}
+	for k := range c.s {
+		for i := 0; i < len(c.s[k]); i += 2 {
+			next()
+			c.s[k][i], c.s[k][i+1] = l, r
+		}
+	}
+}
+
+func bcryptSum(password, salt []byte, cost int) []byte {
+	key := append(append(make([]byte, 0, len(password)+1), password...), 0)
+	if len(key) > bcryptMaxKey {
+		key = key[:bcryptMaxKey]
+	}
+	c := &blowfish{p: bfP, s: [4][256]uint32{bfS0, bfS1, bfS2, bfS3}}
+	c.expand(key, salt)
+	for i := uint64(0); i < 1<<uint(cost); i++ {
+		c.expand(key, nil)
+		c.expand(salt, nil)
+	}
+	b := []byte("OrpheanBeholderScryDoubt")
+	for i := 0; i < len(b); i += 8 {
+		l, r := binary.BigEndian.Uint32(b[i:]), binary.BigEndian.Uint32(b[i+4:])
+		for j := 0; j < 64; j++ {
+			l, r = c.encrypt(l, r)
+		}
+		binary.BigEndian.PutUint32(b[i:], l)
+		binary.BigEndian.PutUint32(b[i+4:], r)
+	}
+	return b[:23]
+}
+
+// check password against $2x$cc$<salt><hash>
+func bcryptVerify(password, stored string) error {
+	if len(stored) != 7+bcryptSaltLen+bcryptHashLen || stored[0] != '$' || stored[1] != '2' || stored[3] != '$' || stored[6] != '$' {
+		return errBcrypt
+	}
+	switch stored[2] {
+	case 'a', 'b', 'y':
+	default:
+		return errPwScheme
+	}
+	cost, err := strconv.Atoi(stored[4:6])
+	if err != nil || cost < bcryptMinCost || cost > bcryptMaxCost {
+		return errBcrypt
+	}
+	salt, err := bcryptB64.DecodeString(stored[7 : 7+bcryptSaltLen])
+	if err != nil {
+		return errBcrypt
+	}
+	want := bcryptB64.EncodeToString(bcryptSum([]byte(password), salt, cost))
+	if subtle.ConstantTimeCompare([]byte(want), []byte(stored[7+bcryptSaltLen:])) != 1 {
+		return errBadPAP
+	}
+	return nil
+}
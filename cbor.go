@@ -0,0 +1,135 @@
+package radius
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// CBOR (RFC 8949) encoding of decoded packet:
+//
+//	{"code": uint, "id": uint, "auth": bytes, "attrs": [attr...]}
+//	attr: {"type": uint, ["ext": uint,] ["vid": uint, "vtype": uint,] ["tag": uint,] ["name": text,] "value": any}
+//
+// ext is extended type of extended attrs (RFC 6929), vid and vtype are set
+// for VSAs and Extended-Vendor-Specific attrs.
+//
+// Values of known attrs are encoded by type: strings and addresses as text,
+// integers as uint, dates as epoch time (tag 1), everything else as bytes.
+// Values of secret attrs are masked unless Packet.SetShowSecrets is on.
+
+const (
+	cborUint  = 0 << 5
+	cborBytes = 2 << 5
+	cborText  = 3 << 5
+	cborArray = 4 << 5
+	cborMap   = 5 << 5
+	cborTag   = 6 << 5
+)
+
+func cborHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xff:
+		return append(b, major|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+func cborAppendText(b []byte, s string) []byte {
+	return append(cborHead(b, cborText, uint64(len(s))), s...)
+}
+
+func cborAppendBytes(b []byte, v []byte) []byte {
+	return append(cborHead(b, cborBytes, uint64(len(v))), v...)
+}
+
+func cborAppendValue(b []byte, v interface{}) []byte {
+	switch av := v.(type) {
+	case []byte:
+		return cborAppendBytes(b, av)
+	case string:
+		return cborAppendText(b, av)
+	case byte:
+		return cborHead(b, cborUint, uint64(av))
+	case uint16:
+		return cborHead(b, cborUint, uint64(av))
+	case uint32:
+		return cborHead(b, cborUint, uint64(av))
+	case uint64:
+		return cborHead(b, cborUint, av)
+	case time.Time:
+		return cborHead(cborHead(b, cborTag, 1), cborUint, uint64(av.Unix()))
+	case net.IP:
+		return cborAppendText(b, av.String())
+	case net.HardwareAddr:
+		return cborAppendText(b, av.String())
+	default:
+		return cborAppendText(b, fmt.Sprint(av))
+	}
+}
+
+func (a *Attr) appendCBOR(b []byte, show bool) []byte {
+	vsa := a.IsVSA() || a.IsEVS()
+	n := uint64(2)
+	if a.IsExtended() {
+		n++
+	}
+	if vsa {
+		n += 2
+	}
+	if a.ad.IsTagged() {
+		n++
+	}
+	if a.ad != nil {
+		n++
+	}
+	b = cborHead(b, cborMap, n)
+	b = cborHead(cborAppendText(b, "type"), cborUint, uint64(a.atype))
+	if a.IsExtended() {
+		b = cborHead(cborAppendText(b, "ext"), cborUint, uint64(a.GetExtType()))
+	}
+	if vsa {
+		b = cborHead(cborAppendText(b, "vid"), cborUint, uint64(a.vid))
+		b = cborHead(cborAppendText(b, "vtype"), cborUint, uint64(a.vtype))
+	}
+	if a.ad.IsTagged() {
+		b = cborHead(cborAppendText(b, "tag"), cborUint, uint64(a.tag))
+	}
+	if a.ad != nil {
+		b = cborAppendText(cborAppendText(b, "name"), a.ad.name)
+	}
+	b = cborAppendText(b, "value")
+	if !show && a.attrData().IsSecret() {
+		return cborAppendText(b, redactMask)
+	}
+	return cborAppendValue(b, a.GetEData())
+}
+
+// Append CBOR encoding of packet to b
+func (p *Packet) AppendCBOR(b []byte) []byte {
+	if p == nil {
+		return append(b, 0xf6) // null
+	}
+	b = cborHead(b, cborMap, 4)
+	b = cborHead(cborAppendText(b, "code"), cborUint, uint64(p.code))
+	b = cborHead(cborAppendText(b, "id"), cborUint, uint64(p.id))
+	b = cborAppendBytes(cborAppendText(b, "auth"), p.auth)
+	attrs := p.attrList()
+	b = cborHead(cborAppendText(b, "attrs"), cborArray, uint64(len(attrs)))
+	for _, a := range attrs {
+		b = a.appendCBOR(b, p.show)
+	}
+	return b
+}
+
+func (p *Packet) MarshalCBOR() ([]byte, error) {
+	return p.AppendCBOR(nil), nil
+}
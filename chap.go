@@ -0,0 +1,75 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"errors"
+)
+
+const chapLen = 17 // CHAP-Password value, ident and MD5 response
+
+var (
+	errNoCHAP  = errors.New("CHAP-Password missing")
+	errBadCHAP = errors.New("CHAP-Password mismatch")
+)
+
+// MD5(ident + password + challenge) (RFC 1994)
+func chapResponse(ident byte, password, challenge []byte) []byte {
+	h := newHash(crypto.MD5)
+	h.Write([]byte{ident})
+	h.Write(password)
+	h.Write(challenge)
+	return h.Sum(nil)
+}
+
+// CHAP challenge, CHAP-Challenge or Request Authenticator if absent
+func (p *Packet) chapChallenge() []byte {
+	if a := p.GetAttr(AttrCHAPChallenge); a != nil {
+		return a.data
+	}
+	return p.auth
+}
+
+// Set CHAP-Password with random ident. CHAP-Challenge is used as challenge,
+// if absent Request Authenticator is, it is generated if not set.
+func (p *Packet) SetCHAPPassword(password string) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if !p.HasAttr(AttrCHAPChallenge) && len(p.auth) != authLen {
+		if _, err := p.GenAuth(); err != nil {
+			return err
+		}
+	}
+	var ident [1]byte
+	if err := p.randRead(ident[:]); err != nil {
+		return err
+	}
+	v := make([]byte, 0, chapLen)
+	v = append(v, ident[0])
+	v = append(v, chapResponse(ident[0], []byte(password), p.chapChallenge())...)
+	return p.setStd(AttrCHAPPassword, v)
+}
+
+// Verify CHAP-Password of request against clear text password
+func (p *Packet) VerifyCHAP(password string) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	a := p.GetAttr(AttrCHAPPassword)
+	if a == nil {
+		return errNoCHAP
+	}
+	if len(a.data) != chapLen {
+		return errors.New("Invalid CHAP-Password")
+	}
+	ch := p.chapChallenge()
+	if len(ch) == 0 {
+		return errors.New("CHAP challenge missing")
+	}
+	r := chapResponse(a.data[0], []byte(password), ch)
+	if subtle.ConstantTimeCompare(r, a.data[1:]) != 1 {
+		return errBadCHAP
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package radius
+
+import (
+	"encoding/binary"
+)
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
+
+// Deep copy of packet, detached from parse buffer
+func (p *Packet) Clone() *Packet {
+	np := p.shallowCopy()
+	np.Detach()
+	return np
+}
+
+// Copy packet and attr data out of parse buffer, so the buffer can be
+// reused or pooled. Decoded value cache is dropped as it may point into buffer.
+func (p *Packet) Detach() {
+	if p == nil {
+		return
+	}
+	if p.data != nil { // len of parsed packet, p.len is updated by serialize
+		p.data = cloneBytes(p.data[:binary.BigEndian.Uint16(p.data[2:4])])
+	}
+	p.auth = cloneBytes(p.auth)
+	p.rauth = cloneBytes(p.rauth)
+	p.pad = cloneBytes(p.pad)
+	if p.bad != nil {
+		bad := make([]BadAttr, len(p.bad))
+		for i, ba := range p.bad {
+			ba.Data = cloneBytes(ba.Data)
+			bad[i] = ba
+		}
+		p.bad = bad
+	}
+	var raw []byte // wire form of last attr, shared by packed VSAs
+	for _, a := range p.attrs {
+		a.data = cloneBytes(a.data)
+		a.edata = nil
+		if a.raw == nil {
+			continue
+		}
+		if !a.cont {
+			raw = cloneBytes(a.raw)
+		}
+		a.raw = raw
+	}
+}
@@ -0,0 +1,241 @@
+package radius
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Combined view of authorized session and its accounting
+type SessionView struct {
+	ID         string    // Correlator session id
+	UserName   string    // User-Name from request or accept
+	StationID  string    // Calling-Station-Id from request
+	Class      [][]byte  // Class values from accept
+	CUI        []byte    // Chargeable-User-Identity
+	SessionID  string    // Acct-Session-Id, set by first accounting packet
+	AuthTime   time.Time // Access-Accept seen
+	StartTime  time.Time // Accounting Start seen
+	UpdateTime time.Time // Last accounting packet seen
+	StopTime   time.Time // Accounting Stop seen
+	Updates    int       // Accounting packets matched
+	Keys       []string  // Correlation keys
+}
+
+// Links Access-Accepts to subsequent accounting by Class, CUI,
+// User-Name + Calling-Station-Id and then Acct-Session-Id.
+// Sessions are kept in Store as JSON, so it can be shared between instances,
+// session updates are atomic with Store.CompareAndSwap.
+type Correlator struct {
+	TTL     time.Duration      // Session lifetime since last update, 0 - forever
+	OnStart func(*SessionView) // Called on accounting Start
+	OnStop  func(*SessionView) // Called on accounting Stop
+	Now     func() time.Time   // Clock, nil - time.Now
+	store   Store
+}
+
+func NewCorrelator() *Correlator {
+	return NewCorrelatorStore(NewMemStore())
+}
+
+func NewCorrelatorStore(s Store) *Correlator {
+	return &Correlator{
+		store: s,
+	}
+}
+
+const (
+	corrViewPfx = "corr:sv:"  // session view by id
+	corrKeyPfx  = "corr:key:" // session id by correlation key
+)
+
+func (c *Correlator) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// session view and its stored form for save and remove, nil if not found
+func (c *Correlator) load(id string) (*SessionView, []byte) {
+	b, ok := c.store.Get(corrViewPfx + id)
+	if !ok {
+		return nil, nil
+	}
+	sv := &SessionView{}
+	if json.Unmarshal(b, sv) != nil {
+		return nil, nil
+	}
+	return sv, b
+}
+
+// store session view if stored one is still old (nil - new session),
+// false if it was updated by someone else
+func (c *Correlator) save(sv *SessionView, old []byte) bool {
+	b, err := json.Marshal(sv)
+	if err != nil {
+		return true // not retried
+	}
+	if !c.store.CompareAndSwap(corrViewPfx+sv.ID, old, b, c.TTL) {
+		return false
+	}
+	for _, k := range sv.Keys {
+		c.store.Set(corrKeyPfx+k, []byte(sv.ID), c.TTL)
+	}
+	return true
+}
+
+// delete session view if stored one is still old, see save
+func (c *Correlator) remove(sv *SessionView, old []byte) bool {
+	if !c.store.CompareAndSwap(corrViewPfx+sv.ID, old, nil, 0) {
+		return false
+	}
+	for _, k := range sv.Keys { // unless key points to another session
+		c.store.CompareAndSwap(corrKeyPfx+k, []byte(sv.ID), nil, 0)
+	}
+	return true
+}
+
+func (c *Correlator) lookup(key string) (*SessionView, []byte) {
+	id, ok := c.store.Get(corrKeyPfx + key)
+	if !ok {
+		return nil, nil
+	}
+	return c.load(string(id))
+}
+
+// session by Acct-Session-Id or first of correlation keys
+func (c *Correlator) find(sid string, keys []string) (sv *SessionView, old []byte) {
+	if len(sid) != 0 {
+		sv, old = c.lookup("sid:" + sid)
+	}
+	for i := 0; sv == nil && i < len(keys); i++ {
+		sv, old = c.lookup(keys[i])
+	}
+	return
+}
+
+func attrString(p *Packet, at AttrType) string {
+	if a := p.GetAttr(at); a != nil {
+		return string(a.data)
+	}
+	return ""
+}
+
+func attrBytes(p *Packet, at AttrType) []byte {
+	if a := p.GetAttr(at); a != nil {
+		return append([]byte(nil), a.data...)
+	}
+	return nil
+}
+
+func hintKey(user, station string) string {
+	if len(user) == 0 || len(station) == 0 {
+		return ""
+	}
+	return "hint:" + user + "\x00" + station
+}
+
+// correlation keys from Class and CUI attrs
+func corrKeys(p *Packet) (keys []string) {
+	for _, a := range p.attrList() {
+		switch a.atype {
+		case AttrClass:
+			keys = append(keys, "class:"+string(a.data))
+		case AttrCUI:
+			if len(a.data) != 0 && !(len(a.data) == 1 && a.data[0] == 0) {
+				keys = append(keys, "cui:"+string(a.data))
+			}
+		}
+	}
+	return
+}
+
+// Register Access-Accept, req is the Access-Request (may be nil)
+func (c *Correlator) Accept(req, accept *Packet) (*SessionView, error) {
+	var id [8]byte
+
+	if err := accept.randRead(id[:]); err != nil {
+		return nil, err
+	}
+	sv := &SessionView{
+		ID:       hex.EncodeToString(id[:]),
+		UserName: attrString(accept, AttrUserName),
+		CUI:      attrBytes(accept, AttrCUI),
+		AuthTime: c.now(),
+	}
+	if len(sv.UserName) == 0 {
+		sv.UserName = attrString(req, AttrUserName)
+	}
+	sv.StationID = attrString(req, AttrCallingStationID)
+	for _, a := range accept.attrList() {
+		if a.atype == AttrClass {
+			sv.Class = append(sv.Class, append([]byte(nil), a.data...))
+		}
+	}
+	sv.Keys = corrKeys(accept)
+	if k := hintKey(sv.UserName, sv.StationID); len(k) != 0 {
+		sv.Keys = append(sv.Keys, k)
+	}
+	c.save(sv, nil)
+	return sv, nil
+}
+
+// Match accounting packet to session, nil if not found
+func (c *Correlator) Accounting(p *Packet) *SessionView {
+	var (
+		sv *SessionView
+		st uint32
+	)
+
+	if a := p.GetAttr(AttrAcctStatusType); a != nil && len(a.data) == 4 {
+		st = binary.BigEndian.Uint32(a.data)
+	}
+	sid := attrString(p, AttrAcctSessionID)
+	keys := corrKeys(p)
+	if k := hintKey(attrString(p, AttrUserName), attrString(p, AttrCallingStationID)); len(k) != 0 {
+		keys = append(keys, k)
+	}
+	now := c.now()
+	for done := false; !done; { // retry if session was updated meanwhile
+		var old []byte
+		if sv, old = c.find(sid, keys); sv == nil {
+			return nil
+		}
+		if len(sv.SessionID) == 0 && len(sid) != 0 {
+			sv.SessionID = sid
+			sv.Keys = append(sv.Keys, "sid:"+sid)
+		}
+		sv.UpdateTime = now
+		sv.Updates++
+		switch st {
+		case AcctStatusStart:
+			sv.StartTime = now
+		case AcctStatusStop:
+			sv.StopTime = now
+		}
+		if st == AcctStatusStop {
+			done = c.remove(sv, old)
+		} else {
+			done = c.save(sv, old)
+		}
+	}
+	var cb func(*SessionView)
+	switch st {
+	case AcctStatusStart:
+		cb = c.OnStart
+	case AcctStatusStop:
+		cb = c.OnStop
+	}
+	if cb != nil {
+		cb(sv)
+	}
+	return sv
+}
+
+// Get session by Acct-Session-Id
+func (c *Correlator) Get(sessionID string) *SessionView {
+	sv, _ := c.lookup("sid:" + sessionID)
+	return sv
+}
@@ -0,0 +1,347 @@
+package radius
+
+import (
+	"crypto"
+	"encoding/binary"
+	"errors"
+)
+
+// Encryption of attr values by AttrEnc. Value of encrypted attr added by
+// application is kept in plain text and encrypted on serialize, value of
+// parsed attr is kept encrypted and decrypted on demand, see Attr.Decrypt.
+
+const (
+	encBlock   = 16   // cipher block len
+	maxUsrLen  = 128  // max User-Password len (RFC 2865 5.2)
+	tunSaltLen = 2    // Tunnel-Password salt len
+	tunSaltBit = 0x80 // salt high bit is always set (RFC 2868 3.5)
+)
+
+var (
+	errNoSecret  = errors.New("Secret required for encrypted attr")
+	errNoAuth    = errors.New("Authenticator required for encrypted attr")
+	errNoReqAuth = errors.New("Request Authenticator required for encrypted attr of reply")
+	errEncLen    = errors.New("Invalid encrypted attr len")
+	errEncNone   = errors.New("Attr is not encrypted")
+)
+
+// encryption is implemented on serialize for enc
+func encSupported(enc AttrEnc) bool {
+	return enc == AttrEncUsr || enc == AttrEncTun || enc == AttrEncAsc
+}
+
+// len of n bytes of plain text encrypted with enc
+func encLen(enc AttrEnc, n int) int {
+	switch enc {
+	case AttrEncUsr:
+		if n == 0 {
+			return encBlock
+		}
+		return (n + encBlock - 1) / encBlock * encBlock
+	case AttrEncTun:
+		return tunSaltLen + (n+encBlock)/encBlock*encBlock // salt, len byte and padding
+	case AttrEncAsc:
+		return encBlock
+	}
+	return n
+}
+
+// plain text len fits enc
+func encLenOK(enc AttrEnc, n int) bool {
+	switch enc {
+	case AttrEncUsr:
+		return n <= maxUsrLen
+	case AttrEncAsc:
+		return n <= encBlock
+	}
+	return true // Tunnel-Password is limited by attr len
+}
+
+// MD5(secret + b)
+func encHash(secret, b []byte) []byte {
+	h := newHash(crypto.MD5)
+	h.Write(secret)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// RFC 2865 5.2, plain text is padded with zeros to 16 bytes blocks,
+// each block is XORed with MD5(secret + previous block), first block
+// uses authenticator
+func encryptUsr(pw, secret, auth []byte) ([]byte, error) {
+	if len(pw) > maxUsrLen {
+		return nil, errEncLen
+	}
+	c := make([]byte, encLen(AttrEncUsr, len(pw)))
+	copy(c, pw)
+	prev := auth
+	for i := 0; i < len(c); i += encBlock {
+		h := encHash(secret, prev)
+		for j := range h {
+			c[i+j] ^= h[j]
+		}
+		prev = c[i : i+encBlock]
+	}
+	return c, nil
+}
+
+// reverse of encryptUsr, zero padding is removed
+func decryptUsr(c, secret, auth []byte) ([]byte, error) {
+	if len(c) == 0 || len(c)%encBlock != 0 || len(c) > maxUsrLen {
+		return nil, errEncLen
+	}
+	pw := make([]byte, len(c))
+	prev := auth
+	for i := 0; i < len(c); i += encBlock {
+		h := encHash(secret, prev)
+		for j := range h {
+			pw[i+j] = c[i+j] ^ h[j]
+		}
+		prev = c[i : i+encBlock]
+	}
+	n := len(pw)
+	for n > 0 && pw[n-1] == 0 {
+		n--
+	}
+	return pw[:n], nil
+}
+
+// RFC 2868 3.5, salt is followed by encrypted len byte, plain text and
+// zero padding, first block is XORed with MD5(secret + auth + salt),
+// next ones as in encryptUsr. Tag is not a part of value.
+func encryptTun(pw, secret, auth, salt []byte) ([]byte, error) {
+	if len(pw) > 0xff {
+		return nil, errEncLen
+	}
+	c := make([]byte, encLen(AttrEncTun, len(pw)))
+	copy(c, salt)
+	c[tunSaltLen] = byte(len(pw))
+	copy(c[tunSaltLen+1:], pw)
+	prev := append(append(make([]byte, 0, authLen+tunSaltLen), auth...), salt...)
+	for i := tunSaltLen; i < len(c); i += encBlock {
+		h := encHash(secret, prev)
+		for j := range h {
+			c[i+j] ^= h[j]
+		}
+		prev = c[i : i+encBlock]
+	}
+	return c, nil
+}
+
+// reverse of encryptTun, plain text is cut by len byte
+func decryptTun(c, secret, auth []byte) ([]byte, error) {
+	if len(c) < tunSaltLen+encBlock || (len(c)-tunSaltLen)%encBlock != 0 {
+		return nil, errEncLen
+	}
+	pw := make([]byte, len(c)-tunSaltLen)
+	prev := append(append(make([]byte, 0, authLen+tunSaltLen), auth...), c[:tunSaltLen]...)
+	for i := tunSaltLen; i < len(c); i += encBlock {
+		h := encHash(secret, prev)
+		for j := range h {
+			pw[i-tunSaltLen+j] = c[i+j] ^ h[j]
+		}
+		prev = c[i : i+encBlock]
+	}
+	n := int(pw[0])
+	if n > len(pw)-1 {
+		return nil, errEncLen
+	}
+	return pw[1 : n+1], nil
+}
+
+// MD5(auth + secret), Ascend keystream (FreeRADIUS make_secret)
+func ascHash(secret, auth []byte) []byte {
+	h := newHash(crypto.MD5)
+	h.Write(auth)
+	h.Write(secret)
+	return h.Sum(nil)
+}
+
+// Ascend secret (FreeRADIUS encrypt=3), value up to 16 bytes is XORed with
+// MD5(auth + secret), result is always 16 bytes
+func encryptAsc(v, secret, auth []byte) ([]byte, error) {
+	if len(v) > encBlock {
+		return nil, errEncLen
+	}
+	c := ascHash(secret, auth)
+	for i := range v {
+		c[i] ^= v[i]
+	}
+	return c, nil
+}
+
+// reverse of encryptAsc, zero padding is removed
+func decryptAsc(c, secret, auth []byte) ([]byte, error) {
+	if len(c) != encBlock {
+		return nil, errEncLen
+	}
+	v := ascHash(secret, auth)
+	for i := range v {
+		v[i] ^= c[i]
+	}
+	n := len(v)
+	for n > 0 && v[n-1] == 0 {
+		n--
+	}
+	return v[:n], nil
+}
+
+// Tunnel-Password salt with high bit set, first one is random, next ones
+// are incremented so salts are unique in packet
+func (p *Packet) nextSalt(prev []byte) ([]byte, error) {
+	salt := make([]byte, tunSaltLen)
+	if prev == nil {
+		if err := p.randRead(salt); err != nil {
+			return nil, err
+		}
+	} else {
+		binary.BigEndian.PutUint16(salt, binary.BigEndian.Uint16(prev)+1)
+	}
+	salt[0] |= tunSaltBit
+	return salt, nil
+}
+
+// authenticator used by attr encryption, request Authenticator for
+// requests and replies, zero for requests with calculated Authenticator.
+// Authenticator of parsed reply is Response Authenticator, request one
+// must be set with SetRequestAuth or VerifyReply.
+func (p *Packet) encAuth() ([]byte, error) {
+	switch {
+	case zeroAuthCode(p.code):
+		return zeroAuth[:], nil
+	case respAuthCode(p.code) && p.data != nil:
+		if len(p.rauth) != authLen {
+			return nil, errNoReqAuth
+		}
+		return p.rauth, nil
+	case len(p.auth) != authLen:
+		return nil, errNoAuth
+	}
+	return p.auth, nil
+}
+
+func encrypt(enc AttrEnc, v, secret, auth, salt []byte) ([]byte, error) {
+	switch enc {
+	case AttrEncUsr:
+		return encryptUsr(v, secret, auth)
+	case AttrEncTun:
+		return encryptTun(v, secret, auth, salt)
+	case AttrEncAsc:
+		return encryptAsc(v, secret, auth)
+	}
+	return nil, errors.New("Encryption not supported")
+}
+
+func decrypt(enc AttrEnc, c, secret, auth []byte) ([]byte, error) {
+	switch enc {
+	case AttrEncNone:
+		return nil, errEncNone
+	case AttrEncUsr:
+		return decryptUsr(c, secret, auth)
+	case AttrEncTun:
+		return decryptTun(c, secret, auth)
+	case AttrEncAsc:
+		return decryptAsc(c, secret, auth)
+	}
+	return nil, errors.New("Encryption not supported")
+}
+
+// encrypt plain text attrs before serialize
+func (p *Packet) encryptAttrs() error {
+	var auth, salt []byte
+	for _, a := range p.attrList() {
+		if !a.clear {
+			continue
+		}
+		if len(p.secret) == 0 {
+			return errNoSecret
+		}
+		if auth == nil {
+			var err error
+			if auth, err = p.encAuth(); err != nil {
+				return err
+			}
+		}
+		enc := a.attrData().GetEnc()
+		if enc == AttrEncTun {
+			var err error
+			if salt, err = p.nextSalt(salt); err != nil {
+				return err
+			}
+		}
+		c, err := encrypt(enc, a.data, p.secret, auth, salt)
+		if err != nil {
+			return err
+		}
+		a.cdata = c
+	}
+	return nil
+}
+
+// decrypt encrypted attr of another packet copied to p, its ciphertext
+// is not valid for p, so error if it can't be decrypted
+func (a *Attr) reclear(p *Packet) error {
+	if a.clear || a.pkt == nil || a.pkt == p || a.attrData().GetEnc() == AttrEncNone {
+		return nil
+	}
+	v, err := a.Decrypt()
+	if err != nil {
+		return err
+	}
+	a.data, a.clear, a.cdata = v, true, nil
+	return nil
+}
+
+// Plain text value of encrypted attr. Parsed attr is decrypted with packet
+// secret and Authenticator, parsed reply needs request Authenticator set
+// with Packet.SetRequestAuth or recorded by Packet.VerifyReply.
+func (a *Attr) Decrypt() ([]byte, error) {
+	if a.clear {
+		return a.data, nil
+	}
+	p := a.pkt
+	if p == nil || len(p.secret) == 0 {
+		return nil, errNoSecret
+	}
+	auth, err := p.encAuth()
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(a.attrData().GetEnc(), a.data, p.secret, auth)
+}
+
+// Decrypt values of encrypted attrs in Attr.GetEData, on by default
+func (p *Packet) SetDecrypt(on bool) {
+	if p == nil {
+		return
+	}
+	p.nodec = !on
+	p.dropEData()
+}
+
+// Set request Authenticator used to decrypt attrs of parsed reply,
+// reply Authenticator (GetAuth) is not changed
+func (p *Packet) SetRequestAuth(auth []byte) {
+	if p == nil {
+		return
+	}
+	p.rauth = auth
+	p.dropEData()
+}
+
+// drop cached decrypted values, they depend on secret and Authenticator
+func (p *Packet) dropEData() {
+	for _, a := range p.attrs {
+		if a.ad.GetEnc() != AttrEncNone {
+			a.edata = nil
+		}
+	}
+}
+
+// Same as Decrypt with explicit secret and authenticator
+func (a *Attr) DecryptWith(secret, auth []byte) ([]byte, error) {
+	if a.clear {
+		return a.data, nil
+	}
+	return decrypt(a.attrData().GetEnc(), a.data, secret, auth)
+}
@@ -0,0 +1,110 @@
+package radius
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Ascend-Send-Secret known answer, keystream is MD5(auth + secret)
+func TestAscendSecret(t *testing.T) {
+	auth := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	secret := []byte("xyzzy5461")
+	want, _ := hex.DecodeString("c0d8792a51fc31baef68f8baf0c3d433")
+	c, err := encryptAsc([]byte("ascend-pw"), secret, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c, want) {
+		t.Fatalf("encryptAsc = %x, want %x", c, want)
+	}
+	v, err := decryptAsc(c, secret, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v) != "ascend-pw" {
+		t.Fatalf("decryptAsc = %q", v)
+	}
+}
+
+// encrypted attrs of parsed reply need request Authenticator, not the
+// Response Authenticator of reply
+func TestReplyTunnelPassword(t *testing.T) {
+	secret := []byte("xyzzy5461")
+	req, err := NewPacket(AccessRequest, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = req.GenAuth(); err != nil {
+		t.Fatal(err)
+	}
+	r := req.ReplyACK()
+	if err = r.AddAttr(AttrTunnelPassword, 0, 0, 1, "tunnel-pw"); err != nil {
+		t.Fatal(err)
+	}
+	r.AddMsgAuth()
+	b := r.Serialize()
+	p, err := ParsePacket(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetSecret(secret)
+	a := p.GetAttr(AttrTunnelPassword)
+	if _, err = a.Decrypt(); err != errNoReqAuth {
+		t.Fatalf("Decrypt without request Authenticator: %v", err)
+	}
+	if _, ok := a.GetEData().([]byte); !ok {
+		t.Fatalf("GetEData = %v, want raw data", a.GetEData())
+	}
+	if err = p.VerifyReply(req, secret); err != nil {
+		t.Fatal(err)
+	}
+	if v := a.GetEData(); v != "tunnel-pw" {
+		t.Fatalf("GetEData = %v", v)
+	}
+	if !bytes.Equal(p.GetAuth(), b[4:MinPLen]) {
+		t.Fatalf("GetAuth = %x, want Response Authenticator", p.GetAuth())
+	}
+}
+
+// encrypted attr copied to another packet is encrypted for it, source
+// attr is left intact
+func TestAddAttrSimpleReclear(t *testing.T) {
+	req, err := NewPacket(AccessRequest, []byte("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = req.SetUserPassword("pw"); err != nil {
+		t.Fatal(err)
+	}
+	src, err := ParsePacket(req.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := src.GetAttr(AttrUserPassword)
+	dst, _ := NewPacket(AccessRequest, []byte("new"))
+	if err = dst.AddAttrSimple(a); err != errNoSecret {
+		t.Fatalf("AddAttrSimple without source secret: %v", err)
+	}
+	if dst.HasAttr(AttrUserPassword) {
+		t.Fatal("attr added on error")
+	}
+	src.SetSecret([]byte("old"))
+	if err = dst.AddAttrSimple(a); err != nil {
+		t.Fatal(err)
+	}
+	if dst.GetAttr(AttrUserPassword) == a {
+		t.Fatal("attr is shared by packets")
+	}
+	q, err := ParsePacket(dst.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.SetSecret([]byte("new"))
+	if v, err := q.GetAttr(AttrUserPassword).Decrypt(); err != nil || string(v) != "pw" {
+		t.Fatalf("Decrypt = %q, %v", v, err)
+	}
+	if v, err := a.Decrypt(); err != nil || string(v) != "pw" {
+		t.Fatalf("source Decrypt = %q, %v", v, err)
+	}
+}
@@ -0,0 +1,137 @@
+package radius
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+// Session identification from CoA/Disconnect request (RFC 5176 3)
+type SessionIdent struct {
+	UserName         string
+	AcctSessionID    string
+	AcctMultiSession string
+	FramedIP         net.IP
+	FramedIPv6Prefix []byte
+	CallingStationID string
+	NASPort          uint32
+	HasNASPort       bool
+	NASPortID        string
+	CUI              []byte
+}
+
+// Extract session identification attrs, false if there are none
+func (p *Packet) GetSessionIdent() (id *SessionIdent, ok bool) {
+	id = &SessionIdent{}
+	if p == nil {
+		return
+	}
+	for _, a := range p.attrList() {
+		switch a.atype {
+		case AttrUserName:
+			id.UserName = string(a.data)
+		case AttrAcctSessionID:
+			id.AcctSessionID = string(a.data)
+		case AttrAcctMultiSessionID:
+			id.AcctMultiSession = string(a.data)
+		case AttrFramedIPAddress:
+			if len(a.data) != net.IPv4len {
+				continue
+			}
+			id.FramedIP = net.IP(a.data)
+		case AttrFramedIPv6Prefix:
+			id.FramedIPv6Prefix = a.data
+		case AttrCallingStationID:
+			id.CallingStationID = string(a.data)
+		case AttrNASPort:
+			if len(a.data) != 4 {
+				continue
+			}
+			id.NASPort = binary.BigEndian.Uint32(a.data)
+			id.HasNASPort = true
+		case AttrNASPortID:
+			id.NASPortID = string(a.data)
+		case AttrCUI:
+			id.CUI = a.data
+		default:
+			continue
+		}
+		ok = true
+	}
+	return
+}
+
+// Dynamic Authorization Server helper for NAS side (RFC 5176)
+type DAS struct {
+	Secret        []byte
+	NASIP         net.IP // Expected NAS-IP-Address, nil - not checked
+	NASIdentifier string // Expected NAS-Identifier, empty - not checked
+	MultiSession  bool   // Allow request to match several sessions
+	// Candidate secrets during rotation, tried after Secret
+	Secrets [][]byte
+	// Find sessions matching identification
+	Lookup func(id *SessionIdent) []interface{}
+	// Perform disconnect or change, 0 - success
+	Apply func(req *Packet, sessions []interface{}) ErrorCause
+}
+
+var errNoDASLookup = errors.New("DAS Lookup or Apply not set")
+
+// Handle raw CoA-Request or Disconnect-Request.
+// Returns ACK/NAK reply, or error if request must be silently dropped
+// (malformed, not a dynamic authorization request, bad authenticator).
+func (d *DAS) Process(buf []byte) (*Packet, error) {
+	if d.Lookup == nil || d.Apply == nil {
+		return nil, errNoDASLookup
+	}
+	req, err := ParsePacket(buf)
+	if err != nil {
+		return nil, err
+	}
+	if req.code != CoARequest && req.code != DisconnectRequest {
+		return nil, errNotDynAuth
+	}
+	secrets := d.Secrets
+	if d.Secret != nil {
+		secrets = append([][]byte{d.Secret}, secrets...)
+	}
+	if req.secret, err = MatchSecretRaw(buf, secrets); err != nil {
+		return nil, err
+	}
+	if !d.nasMatch(req) {
+		return req.DynAuthNAK(CauseNASIdentMismatch)
+	}
+	id, ok := req.GetSessionIdent()
+	if !ok {
+		return req.DynAuthNAK(CauseMissingAttribute)
+	}
+	sessions := d.Lookup(id)
+	switch {
+	case len(sessions) == 0:
+		return req.DynAuthNAK(CauseSessionNotFound)
+	case len(sessions) > 1 && !d.MultiSession:
+		return req.DynAuthNAK(CauseMultipleSessionSelUnsup)
+	}
+	if ec := d.Apply(req, sessions); ec != 0 {
+		return req.DynAuthNAK(ec)
+	}
+	return req.DynAuthACK()
+}
+
+func (d *DAS) nasMatch(req *Packet) bool {
+	if d.NASIP != nil {
+		if a := req.GetAttr(AttrNASIPAddress); a != nil && !net.IP(a.data).Equal(d.NASIP) {
+			return false
+		}
+		if a := req.GetAttr(AttrNASIPv6Address); a != nil && !net.IP(a.data).Equal(d.NASIP) {
+			return false
+		}
+	}
+	if len(d.NASIdentifier) != 0 {
+		if a := req.GetAttr(AttrNASIdentifier); a != nil && !bytes.Equal(a.data, []byte(d.NASIdentifier)) {
+			return false
+		}
+	}
+	return true
+}
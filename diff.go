@@ -0,0 +1,115 @@
+package radius
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Attr present in one or both of diffed packets, values are decoded
+// as by Attr.GetEData and may share packet data
+type AttrChange struct {
+	Name string
+	Tag  byte
+	Old  interface{} // nil for added attr
+	New  interface{} // nil for removed attr
+	os   string      // Old for output
+	ns   string      // New for output
+}
+
+// Attrs added, removed and changed from packet a to packet b
+type PacketDiff struct {
+	Added   []AttrChange
+	Removed []AttrChange
+	Changed []AttrChange
+}
+
+type diffKey struct {
+	key uint64
+	tag byte
+}
+
+func attrDiffKey(a *Attr) diffKey {
+	return diffKey{key: attrKey(a.atype, a.vid, a.vtype), tag: a.tag}
+}
+
+// Diff attrs of packets. Attrs of the same type and tag are paired in
+// packet order, n-th attr in a with n-th in b, unpaired ones are removed
+// or added. Order of attrs of different types is ignored.
+func Diff(a, b *Packet) *PacketDiff {
+	d := &PacketDiff{}
+	var al, bl []*Attr
+	if a != nil {
+		al = a.attrList()
+	}
+	if b != nil {
+		bl = b.attrList()
+	}
+	bm := make(map[diffKey][]*Attr)
+	for _, ba := range bl {
+		k := attrDiffKey(ba)
+		bm[k] = append(bm[k], ba)
+	}
+	for _, aa := range al {
+		k := attrDiffKey(aa)
+		bs := bm[k]
+		if len(bs) == 0 {
+			d.Removed = append(d.Removed, AttrChange{
+				Name: aa.GetName(),
+				Tag:  aa.tag,
+				Old:  aa.GetEData(),
+				os:   aa.valueString(false),
+			})
+			continue
+		}
+		ba := bs[0]
+		bm[k] = bs[1:]
+		if bytes.Equal(aa.data, ba.data) {
+			continue
+		}
+		d.Changed = append(d.Changed, AttrChange{
+			Name: aa.GetName(),
+			Tag:  aa.tag,
+			Old:  aa.GetEData(),
+			New:  ba.GetEData(),
+			os:   aa.valueString(false),
+			ns:   ba.valueString(false),
+		})
+	}
+	for _, ba := range bl { // keep b order
+		k := attrDiffKey(ba)
+		bs := bm[k]
+		if len(bs) == 0 || bs[0] != ba {
+			continue
+		}
+		bm[k] = bs[1:]
+		d.Added = append(d.Added, AttrChange{
+			Name: ba.GetName(),
+			Tag:  ba.tag,
+			New:  ba.GetEData(),
+			ns:   ba.valueString(false),
+		})
+	}
+	return d
+}
+
+// No attrs differ
+func (d *PacketDiff) Empty() bool {
+	return d == nil || len(d.Added)+len(d.Removed)+len(d.Changed) == 0
+}
+
+// Diff in unified style, secret values are masked
+func (d *PacketDiff) String() (r string) {
+	if d == nil {
+		return
+	}
+	for _, c := range d.Removed {
+		r += fmt.Sprintf("- %s: %s\n", c.Name, c.os)
+	}
+	for _, c := range d.Added {
+		r += fmt.Sprintf("+ %s: %s\n", c.Name, c.ns)
+	}
+	for _, c := range d.Changed {
+		r += fmt.Sprintf("~ %s: %s -> %s\n", c.Name, c.os, c.ns)
+	}
+	return
+}
@@ -0,0 +1,192 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// HTTP Digest authentication over RADIUS (RFC 5090), fields of Digest-*
+// attrs, empty ones are absent
+type DigestAuth struct {
+	Username   string // Digest-Username
+	Realm      string // Digest-Realm
+	Nonce      string // Digest-Nonce
+	Method     string // Digest-Method
+	URI        string // Digest-URI
+	Qop        string // Digest-Qop, auth or auth-int
+	Algorithm  string // Digest-Algorithm, MD5 if empty, or MD5-sess
+	BodyHash   string // Digest-Entity-Body-Hash, H(entity-body) for auth-int
+	CNonce     string // Digest-CNonce
+	NonceCount string // Digest-Nonce-Count
+	Opaque     string // Digest-Opaque
+	Response   string // Digest-Response, 32 hex digits
+}
+
+var (
+	errNoDigest    = errors.New("Digest attrs missing")
+	errBadDigest   = errors.New("Digest-Response mismatch")
+	errDigestNonce = errors.New("Invalid Digest-Nonce")
+	errStaleNonce  = errors.New("Stale Digest-Nonce")
+)
+
+func digestFields(d *DigestAuth) []struct {
+	at AttrType
+	v  *string
+} {
+	return []struct {
+		at AttrType
+		v  *string
+	}{
+		{AttrDigestUsername, &d.Username},
+		{AttrDigestRealm, &d.Realm},
+		{AttrDigestNonce, &d.Nonce},
+		{AttrDigestMethod, &d.Method},
+		{AttrDigestURI, &d.URI},
+		{AttrDigestQop, &d.Qop},
+		{AttrDigestAlgorithm, &d.Algorithm},
+		{AttrDigestEntityBodyHash, &d.BodyHash},
+		{AttrDigestCNonce, &d.CNonce},
+		{AttrDigestNonceCount, &d.NonceCount},
+		{AttrDigestOpaque, &d.Opaque},
+		{AttrDigestResponse, &d.Response},
+	}
+}
+
+// Add Digest-* attrs of non empty fields
+func (p *Packet) AddDigest(d *DigestAuth) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	for _, f := range digestFields(d) {
+		if *f.v == "" {
+			continue
+		}
+		if err := p.addStd(f.at, 0, *f.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Digest-* attrs of packet, nil if there is no Digest-Response
+func (p *Packet) GetDigest() *DigestAuth {
+	if !p.HasAttr(AttrDigestResponse) {
+		return nil
+	}
+	d := &DigestAuth{}
+	for _, f := range digestFields(d) {
+		*f.v, _ = p.GetString(f.at)
+	}
+	return d
+}
+
+func md5Hex(s string) string {
+	return hex.EncodeToString(hashSum(crypto.MD5, []byte(s)))
+}
+
+// H(username:realm:password) (RFC 2617 3.2.2.2)
+func DigestHA1(username, realm, password string) string {
+	return md5Hex(username + ":" + realm + ":" + password)
+}
+
+// session HA1 for MD5-sess, ha1 for MD5
+func (d *DigestAuth) sessHA1(ha1 string) string {
+	if strings.EqualFold(d.Algorithm, "MD5-sess") {
+		return md5Hex(ha1 + ":" + d.Nonce + ":" + d.CNonce)
+	}
+	return ha1
+}
+
+// request digest with A2 of method (empty for rspauth)
+func (d *DigestAuth) digest(ha1, method string) string {
+	a2 := method + ":" + d.URI
+	if d.Qop == "auth-int" {
+		a2 += ":" + d.BodyHash
+	}
+	ha1 = d.sessHA1(ha1)
+	if d.Qop == "" {
+		return md5Hex(ha1 + ":" + d.Nonce + ":" + md5Hex(a2))
+	}
+	return md5Hex(ha1 + ":" + d.Nonce + ":" + d.NonceCount + ":" + d.CNonce + ":" + d.Qop + ":" + md5Hex(a2))
+}
+
+// Set Response from password, client side
+func (d *DigestAuth) SetResponse(password string) {
+	d.Response = d.digest(DigestHA1(d.Username, d.Realm, password), d.Method)
+}
+
+// Value of Digest-Response-Auth (rspauth) for Access-Accept, ha1 is
+// H(username:realm:password)
+func (d *DigestAuth) ResponseAuth(ha1 string) string {
+	return d.digest(ha1, "")
+}
+
+func (d *DigestAuth) check() error {
+	switch {
+	case d.Nonce == "" || d.Method == "" || d.URI == "" || d.Response == "":
+		return errNoDigest
+	case d.Qop != "" && (d.CNonce == "" || d.NonceCount == ""):
+		return errNoDigest
+	case d.Algorithm != "" && !strings.EqualFold(d.Algorithm, "MD5") && !strings.EqualFold(d.Algorithm, "MD5-sess"):
+		return errors.New("Digest algorithm not supported: " + d.Algorithm)
+	}
+	return nil
+}
+
+// Verify Digest-Response of request against clear text password
+func (p *Packet) VerifyDigest(password string) error {
+	d := p.GetDigest()
+	if d == nil {
+		return errNoDigest
+	}
+	return p.VerifyDigestHA1(DigestHA1(d.Username, d.Realm, password))
+}
+
+// Verify Digest-Response of request against stored HA1
+func (p *Packet) VerifyDigestHA1(ha1 string) error {
+	d := p.GetDigest()
+	if d == nil {
+		return errNoDigest
+	}
+	if err := d.check(); err != nil {
+		return err
+	}
+	want := d.digest(strings.ToLower(ha1), d.Method)
+	if subtle.ConstantTimeCompare([]byte(want), []byte(strings.ToLower(d.Response))) != 1 {
+		return errBadDigest
+	}
+	return nil
+}
+
+// Stateless nonce for Digest-Nonce of Access-Challenge: issue time and
+// HMAC-SHA256 of it with server key, hex encoded
+func NewDigestNonce(key []byte, now time.Time) string {
+	b := binary.BigEndian.AppendUint64(nil, uint64(now.Unix()))
+	m := newHMAC(crypto.SHA256, key)
+	m.Write(b)
+	return hex.EncodeToString(m.Sum(b))
+}
+
+// Check nonce made by NewDigestNonce, stale if older than ttl
+func VerifyDigestNonce(nonce string, key []byte, now time.Time, ttl time.Duration) error {
+	b, err := hex.DecodeString(nonce)
+	if err != nil || len(b) != 8+crypto.SHA256.Size() {
+		return errDigestNonce
+	}
+	m := newHMAC(crypto.SHA256, key)
+	m.Write(b[:8])
+	if !hmac.Equal(m.Sum(nil), b[8:]) {
+		return errDigestNonce
+	}
+	t := time.Unix(int64(binary.BigEndian.Uint64(b)), 0)
+	if t.After(now) || now.Sub(t) > ttl {
+		return errStaleNonce
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Package radius implements RADIUS packet parsing and building.
+//
+// Standard attrs of IETF RFCs, extended attrs (RFC 6929) and Microsoft VSAs
+// (RFC 2548) are builtin. ParsePacket, AddAttr and AddAttrByName use them
+// when dictionary has no entry for attr, so values of standard attrs are
+// decoded by data type and tags are split without loading a dictionary.
+// Dictionary entries override builtin ones.
+package radius
@@ -0,0 +1,154 @@
+package radius
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Error-Cause values (RFC 5176)
+type ErrorCause uint32
+
+const (
+	CauseResidualSessionRemoved  ErrorCause = 201
+	CauseInvalidEAPPacket        ErrorCause = 202
+	CauseUnsupportedAttribute    ErrorCause = 401
+	CauseMissingAttribute        ErrorCause = 402
+	CauseNASIdentMismatch        ErrorCause = 403
+	CauseInvalidRequest          ErrorCause = 404
+	CauseUnsupportedService      ErrorCause = 405
+	CauseUnsupportedExtension    ErrorCause = 406
+	CauseInvalidAttributeValue   ErrorCause = 407
+	CauseAdminProhibited         ErrorCause = 501
+	CauseRequestNotRoutable      ErrorCause = 502
+	CauseSessionNotFound         ErrorCause = 503
+	CauseSessionNotRemovable     ErrorCause = 504
+	CauseOtherProxyError         ErrorCause = 505
+	CauseResourcesUnavailable    ErrorCause = 506
+	CauseRequestInitiated        ErrorCause = 507
+	CauseMultipleSessionSelUnsup ErrorCause = 508
+)
+
+func (ec ErrorCause) String() string {
+	switch ec {
+	case CauseResidualSessionRemoved:
+		return "Residual-Session-Context-Removed"
+	case CauseInvalidEAPPacket:
+		return "Invalid-EAP-Packet"
+	case CauseUnsupportedAttribute:
+		return "Unsupported-Attribute"
+	case CauseMissingAttribute:
+		return "Missing-Attribute"
+	case CauseNASIdentMismatch:
+		return "NAS-Identification-Mismatch"
+	case CauseInvalidRequest:
+		return "Invalid-Request"
+	case CauseUnsupportedService:
+		return "Unsupported-Service"
+	case CauseUnsupportedExtension:
+		return "Unsupported-Extension"
+	case CauseInvalidAttributeValue:
+		return "Invalid-Attribute-Value"
+	case CauseAdminProhibited:
+		return "Administratively-Prohibited"
+	case CauseRequestNotRoutable:
+		return "Request-Not-Routable"
+	case CauseSessionNotFound:
+		return "Session-Context-Not-Found"
+	case CauseSessionNotRemovable:
+		return "Session-Context-Not-Removable"
+	case CauseOtherProxyError:
+		return "Other-Proxy-Processing-Error"
+	case CauseResourcesUnavailable:
+		return "Resources-Unavailable"
+	case CauseRequestInitiated:
+		return "Request-Initiated"
+	case CauseMultipleSessionSelUnsup:
+		return "Multiple-Session-Selection-Unsupported"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint32(ec))
+	}
+}
+
+var errNotDynAuth = errors.New("Not a CoA or Disconnect request")
+
+// Session identification attrs (RFC 5176 3), not echoed in ACK/NAK unless
+// passed as echo to DynAuthACK or DynAuthNAK
+var DynAuthSessionAttrs = []AttrType{
+	AttrUserName,
+	AttrAcctSessionID,
+	AttrAcctMultiSessionID,
+	AttrFramedIPAddress,
+	AttrFramedIPv6Prefix,
+	AttrFramedInterfaceID,
+	AttrCallingStationID,
+	AttrCalledStationID,
+	AttrNASPort,
+	AttrNASPortID,
+	AttrCUI,
+}
+
+// ACK for CoA-Request or Disconnect-Request, echo - more request attrs to copy
+// into reply, e.g. DynAuthSessionAttrs. State and Proxy-State of request are
+// always echoed (RFC 5176 3.6).
+func (p *Packet) DynAuthACK(echo ...AttrType) (*Packet, error) {
+	return p.dynAuthReply(true, 0, echo)
+}
+
+// NAK for CoA-Request or Disconnect-Request with Error-Cause (0 - no Error-Cause),
+// echo as in DynAuthACK
+func (p *Packet) DynAuthNAK(cause ErrorCause, echo ...AttrType) (*Packet, error) {
+	return p.dynAuthReply(false, cause, echo)
+}
+
+func (p *Packet) dynAuthReply(ack bool, cause ErrorCause, echo []AttrType) (*Packet, error) {
+	switch p.GetCode() {
+	case DisconnectRequest, CoARequest:
+	default:
+		return nil, errNotDynAuth
+	}
+	r := p.newReply()
+	r.code = replyCode(p.code, ack)
+	if err := p.echoAttrs(r, echo); err != nil {
+		return nil, err
+	}
+	if cause != 0 {
+		if err := r.addStd(AttrErrorCause, 0, uint32(cause)); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.copyAttrs(r, AttrProxyState); err != nil { // must be echoed unmodified
+		return nil, err
+	}
+	return r, nil
+}
+
+// copy State and request attrs of listed types to reply in request order,
+// Proxy-State is skipped, it is copied last
+func (p *Packet) echoAttrs(dst *Packet, echo []AttrType) error {
+	for _, a := range p.attrList() {
+		if a.atype == AttrProxyState || a.atype != AttrState && !slices.Contains(echo, a.atype) {
+			continue
+		}
+		if err := dst.AddAttrSimple(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copy all attrs of type to other packet
+func (p *Packet) copyAttrs(dst *Packet, at AttrType) error {
+	if !p.HasAttr(at) {
+		return nil
+	}
+	for _, a := range p.attrList() {
+		if a.atype != at {
+			continue
+		}
+		if err := dst.AddAttrSimple(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package radius
+
+// Set wire form for attrs parsed from one wire attr, several attrs
+// come from packed VSA and are written once with the first one
+func (p *Packet) setRaw(attrs []*Attr, raw []byte) {
+	for i, a := range attrs {
+		a.raw = raw
+		a.cont = i > 0
+	}
+}
+
+// Drop wire form of attr and other attrs from the same packed VSA,
+// must be called before attr is modified
+func (a *Attr) dropRaw() {
+	if a.raw == nil {
+		return
+	}
+	raw := a.raw
+	if a.pkt == nil {
+		a.raw, a.cont = nil, false
+		return
+	}
+	for _, pa := range a.pkt.attrs {
+		if len(pa.raw) != 0 && &pa.raw[0] == &raw[0] {
+			pa.raw, pa.cont = nil, false
+			pa.setLen()
+		}
+	}
+}
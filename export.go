@@ -0,0 +1,216 @@
+package radius
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Accounting event export format
+type ExportFormat int
+
+const (
+	ExportJSON ExportFormat = iota // JSON Lines
+	ExportCEF                      // ArcSight Common Event Format
+)
+
+const redactMask = "******"
+
+// Flat event exporter, one line per packet with dictionary names as keys.
+// Secret attrs are always redacted, see SetAttrSecret.
+type Exporter struct {
+	Format  ExportFormat
+	Fields  map[string]string // attr name -> output field name, case insensitive
+	Only    bool              // export only attrs listed in Fields
+	Redact  []string          // attr names to mask
+	Vendor  string            // CEF device vendor
+	Product string            // CEF device product
+	Version string            // CEF device version
+}
+
+type exportField struct {
+	key  string
+	vals []string // JSON encoded for JSON, raw for CEF
+}
+
+func (e *Exporter) fieldName(a *Attr) (string, bool) {
+	name := a.GetName()
+	if key, ok := e.Fields[name]; ok {
+		return key, true
+	}
+	for n, key := range e.Fields {
+		if strings.EqualFold(n, name) {
+			return key, true
+		}
+	}
+	return name, !e.Only
+}
+
+func (e *Exporter) redacted(a *Attr) bool {
+	if a.attrData().IsSecret() {
+		return true
+	}
+	name := a.GetName()
+	for _, n := range e.Redact {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func exportValue(v interface{}) string {
+	switch av := v.(type) {
+	case []byte:
+		return hex.EncodeToString(av)
+	case string:
+		return av
+	case time.Time:
+		return av.UTC().Format(time.RFC3339)
+	case net.IP:
+		return av.String()
+	default:
+		if s, ok := v.(interface{ String() string }); ok {
+			return s.String()
+		}
+		return strings.Trim(jsonString(av), `"`)
+	}
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return `""`
+	}
+	return string(b)
+}
+
+func (e *Exporter) fields(p *Packet) (fl []*exportField) {
+	idx := make(map[string]*exportField)
+	for _, a := range p.attrList() {
+		key, ok := e.fieldName(a)
+		if !ok {
+			continue
+		}
+		var v string
+		switch {
+		case e.redacted(a):
+			v = redactMask
+			if e.Format == ExportJSON {
+				v = jsonString(v)
+			}
+		case e.Format == ExportCEF:
+			v = exportValue(a.GetEData())
+		default:
+			switch ed := a.GetEData().(type) {
+			case byte, uint16, uint32, uint64:
+				v = jsonString(ed)
+			default:
+				v = jsonString(exportValue(ed))
+			}
+		}
+		if f, ok := idx[key]; ok {
+			f.vals = append(f.vals, v)
+			continue
+		}
+		f := &exportField{key: key, vals: []string{v}}
+		idx[key] = f
+		fl = append(fl, f)
+	}
+	return
+}
+
+// Append one event line for packet to b
+func (e *Exporter) Append(b []byte, p *Packet) ([]byte, error) {
+	if p == nil {
+		return b, errors.New("Packet empty")
+	}
+	switch e.Format {
+	case ExportJSON:
+		return e.appendJSON(b, p), nil
+	case ExportCEF:
+		return e.appendCEF(b, p), nil
+	}
+	return b, errors.New("Unknown export format")
+}
+
+// Write one event line for packet to w
+func (e *Exporter) Write(w io.Writer, p *Packet) error {
+	b, err := e.Append(nil, p)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (e *Exporter) appendJSON(b []byte, p *Packet) []byte {
+	b = append(b, `{"Packet-Type":`...)
+	b = append(b, jsonString(p.code.String())...)
+	b = append(b, `,"Packet-Id":`...)
+	b = strconv.AppendUint(b, uint64(p.id), 10)
+	for _, f := range e.fields(p) {
+		b = append(b, ',')
+		b = append(b, jsonString(f.key)...)
+		b = append(b, ':')
+		if len(f.vals) == 1 {
+			b = append(b, f.vals[0]...)
+			continue
+		}
+		b = append(b, '[')
+		b = append(b, strings.Join(f.vals, ",")...)
+		b = append(b, ']')
+	}
+	return append(b, '}', '\n')
+}
+
+var (
+	cefHeaderEsc = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefValueEsc  = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+// CEF keys are alphanumeric
+func cefKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func (e *Exporter) appendCEF(b []byte, p *Packet) []byte {
+	sig := strconv.Itoa(int(p.code))
+	if a := p.GetAttr(AttrAcctStatusType); a != nil {
+		if v, ok := a.GetEData().(uint32); ok {
+			sig += "-" + strconv.FormatUint(uint64(v), 10)
+		}
+	}
+	b = append(b, "CEF:0|"...)
+	b = append(b, cefHeaderEsc.Replace(e.Vendor)...)
+	b = append(b, '|')
+	b = append(b, cefHeaderEsc.Replace(e.Product)...)
+	b = append(b, '|')
+	b = append(b, cefHeaderEsc.Replace(e.Version)...)
+	b = append(b, '|')
+	b = append(b, sig...)
+	b = append(b, '|')
+	b = append(b, cefHeaderEsc.Replace(p.code.String())...)
+	b = append(b, "|0|"...)
+	sep := false
+	for _, f := range e.fields(p) {
+		if sep {
+			b = append(b, ' ')
+		}
+		sep = true
+		b = append(b, cefKey(f.key)...)
+		b = append(b, '=')
+		b = append(b, cefValueEsc.Replace(strings.Join(f.vals, ","))...)
+	}
+	return append(b, '\n')
+}
@@ -0,0 +1,266 @@
+package radius
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Extended attr spaces (RFC 6929), extended type is kept in vtype.
+// Extended-Vendor-Specific attr keeps vendor ID and type in vid and vtype.
+const (
+	AttrExtended1     AttrType = 241
+	AttrExtended2     AttrType = 242
+	AttrExtended3     AttrType = 243
+	AttrExtended4     AttrType = 244
+	AttrLongExtended1 AttrType = 245
+	AttrLongExtended2 AttrType = 246
+)
+
+// Standard extended attrs
+const (
+	ExtFragStatus         VendorType = 1 // 241.1 (RFC 7499)
+	ExtProxyStateLength   VendorType = 2 // 241.2 (RFC 7499)
+	ExtResponseLength     VendorType = 3 // 241.3 (RFC 7930)
+	ExtOriginalPacketCode VendorType = 4 // 241.4 (RFC 7930)
+	ExtEVS                VendorType = 26
+)
+
+const evsHdrLen = 5 // EVS vendor ID and type
+
+const extMore = 0x80 // Long-Extended-Type More flag, value continues in next attr
+
+var (
+	errExtShort = errors.New("Extended attr too short")
+	errExtType  = errors.New("Extended type out of range")
+)
+
+func isExtended(at AttrType) bool {
+	return at >= AttrExtended1 && at <= AttrLongExtended2
+}
+
+func isLongExtended(at AttrType) bool {
+	return at == AttrLongExtended1 || at == AttrLongExtended2
+}
+
+func (a *Attr) IsExtended() bool {
+	return isExtended(a.atype)
+}
+
+// Extended-Vendor-Specific attr, N.26
+func (a *Attr) IsEVS() bool {
+	return a.IsExtended() && a.vid != 0
+}
+
+// Extended type of attr from extended space, 241.N is N
+func (a *Attr) GetExtType() byte {
+	if a.IsEVS() {
+		return byte(ExtEVS)
+	}
+	return byte(a.vtype)
+}
+
+// EVS header len in value
+func (a *Attr) evsLen() int {
+	if a.IsEVS() {
+		return evsHdrLen
+	}
+	return 0
+}
+
+// append EVS header if n is not 0
+func (a *Attr) appendEVS(b []byte, n int) []byte {
+	if n == 0 {
+		return b
+	}
+	b = binary.BigEndian.AppendUint32(b, uint32(a.vid))
+	return append(b, byte(a.vtype))
+}
+
+func rfcExtAttr(name string, atype AttrType, ext VendorType, dtype AttrDType) *AttrData {
+	return &AttrData{name: name, atype: atype, vtype: ext, dtype: dtype}
+}
+
+// Builtin data for standard extended attrs
+var rfcExtAttrs = map[uint64]*AttrData{
+	attrKey(AttrExtended1, 0, ExtFragStatus):         rfcExtAttr("Frag-Status", AttrExtended1, ExtFragStatus, DTypeInt),
+	attrKey(AttrExtended1, 0, ExtProxyStateLength):   rfcExtAttr("Proxy-State-Length", AttrExtended1, ExtProxyStateLength, DTypeInt),
+	attrKey(AttrExtended1, 0, ExtResponseLength):     rfcExtAttr("Response-Length", AttrExtended1, ExtResponseLength, DTypeInt),
+	attrKey(AttrExtended1, 0, ExtOriginalPacketCode): rfcExtAttr("Original-Packet-Code", AttrExtended1, ExtOriginalPacketCode, DTypeInt),
+}
+
+// Attr data of extended attr from dictionary or builtin standard attr data
+func stdExtAttr(at AttrType, ext VendorType) *AttrData {
+	if ad := GetAttrByExt(at, ext); ad != nil {
+		return ad
+	}
+	return rfcExtAttrs[attrKey(at, 0, ext)]
+}
+
+func AddAttrExt(name string, atype AttrType, ext VendorType, dtype AttrDType) error {
+	if !isExtended(atype) {
+		return errors.New("Not extended attribute: " + name)
+	}
+	return AddAttrFull(name, atype, 0, ext, dtype, AttrEncNone, false)
+}
+
+func MustAddAttrExt(name string, atype AttrType, ext VendorType, dtype AttrDType) {
+	if err := AddAttrExt(name, atype, ext, dtype); err != nil {
+		panic(err)
+	}
+}
+
+func GetAttrByExt(atype AttrType, ext VendorType) *AttrData {
+	return GetAttrByAttrFull(atype, 0, ext)
+}
+
+// Put Extended-Vendor-Specific attr atype.26.vid.vtype in dictionary
+func AddEVS(name string, atype AttrType, vid VendorID, vtype VendorType, dtype AttrDType) error {
+	switch {
+	case !isExtended(atype):
+		return errors.New("Not extended attribute: " + name)
+	case vid == 0 || vtype > 0xff:
+		return errors.New("Invalid EVS: " + name)
+	}
+	return AddAttrFull(name, atype, vid, vtype, dtype, AttrEncNone, false)
+}
+
+func MustAddEVS(name string, atype AttrType, vid VendorID, vtype VendorType, dtype AttrDType) {
+	if err := AddEVS(name, atype, vid, vtype, dtype); err != nil {
+		panic(err)
+	}
+}
+
+func GetEVSByAttr(atype AttrType, vid VendorID, vtype VendorType) *AttrData {
+	return GetAttrByAttrFull(atype, vid, vtype)
+}
+
+// split extended attr value in extended type, More flag and value
+func splitExt(at AttrType, ad []byte) (ext VendorType, more bool, v []byte) {
+	ext, v = VendorType(ad[0]), ad[1:]
+	if isLongExtended(at) {
+		more, v = v[0]&extMore != 0, v[1:]
+	}
+	return
+}
+
+// split EVS value in vendor ID, type and value
+func splitEVS(v []byte) (VendorID, VendorType, []byte, error) {
+	if len(v) < evsHdrLen {
+		return 0, 0, nil, errExtShort
+	}
+	return VendorID(binary.BigEndian.Uint32(v)), VendorType(v[4]), v[evsHdrLen:], nil
+}
+
+// Parse extended attr, join is attr with More flag from previous attr.
+// Returns attr to be continued by next attr and if value was joined.
+func (p *Packet) parseExt(at AttrType, ad []byte, join *Attr) (next *Attr, joined bool, err error) {
+	if err = checkExt(at, ad); err != nil {
+		return
+	}
+	ext, more, v := splitExt(at, ad)
+	attr := join
+	if join != nil && join.atype == at && VendorType(join.GetExtType()) == ext { // next part of long attr
+		join.joinData(v)
+		joined = true
+	} else {
+		var (
+			vid VendorID
+			ad  *AttrData
+		)
+		if ext == ExtEVS {
+			if vid, ext, v, err = splitEVS(v); err != nil {
+				return
+			}
+			ad = GetEVSByAttr(at, vid, ext)
+		} else {
+			ad = stdExtAttr(at, ext)
+		}
+		attr = newAttr(Attr{
+			atype: at,
+			vid:   vid,
+			vtype: ext,
+			ad:    ad,
+			pkt:   p,
+		})
+		attr.tag, attr.data = splitTag(attr.ad, v)
+		attr.setLen()
+		p.appendAttr(attr)
+	}
+	if more {
+		next = attr
+	}
+	return
+}
+
+// check extended attr header
+func checkExt(at AttrType, ad []byte) error {
+	if len(ad) < 1 || isLongExtended(at) && len(ad) < 2 {
+		return errExtShort
+	}
+	return nil
+}
+
+// check extended attr header and EVS header unless attr continues previous
+// one, returns More flag
+func checkExtEVS(at AttrType, ad []byte, cont bool) (bool, error) {
+	if err := checkExt(at, ad); err != nil {
+		return false, err
+	}
+	ext, more, v := splitExt(at, ad)
+	if ext == ExtEVS && !cont && len(v) < evsHdrLen {
+		return false, errExtShort
+	}
+	return more, nil
+}
+
+// First extended attr of space and type, nil if absent
+func (p *Packet) GetExt(at AttrType, ext VendorType) *Attr {
+	if p == nil || !p.HasAttr(at) {
+		return nil
+	}
+	for _, a := range p.attrList() {
+		if a.atype == at && a.vid == 0 && a.vtype == ext {
+			return a
+		}
+	}
+	return nil
+}
+
+// All extended attrs of space and type in packet order
+func (p *Packet) GetExts(at AttrType, ext VendorType) (r []*Attr) {
+	if p == nil || !p.HasAttr(at) {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.atype == at && a.vid == 0 && a.vtype == ext {
+			r = append(r, a)
+		}
+	}
+	return
+}
+
+// First EVS of space, vendor and type, nil if absent
+func (p *Packet) GetEVS(at AttrType, vid VendorID, vtype VendorType) *Attr {
+	if p == nil || !p.HasAttr(at) {
+		return nil
+	}
+	for _, a := range p.attrList() {
+		if a.atype == at && a.vid == vid && a.vtype == vtype {
+			return a
+		}
+	}
+	return nil
+}
+
+// All EVS of space, vendor and type in packet order
+func (p *Packet) GetEVSs(at AttrType, vid VendorID, vtype VendorType) (r []*Attr) {
+	if p == nil || !p.HasAttr(at) {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.atype == at && a.vid == vid && a.vtype == vtype {
+			r = append(r, a)
+		}
+	}
+	return
+}
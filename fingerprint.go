@@ -0,0 +1,73 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/netip"
+)
+
+// Fingerprint format version, part of hashed data
+const fingerprintV1 = "radius-fp-v1"
+
+// Stable SHA-256 hash of code, id, authenticator and attributes.
+// Each attribute is hashed in packet order in normalized form (type,
+// vendor id, vendor type, wire value with tag), so VSA packing and
+// dictionary contents do not affect the result. The format is fixed for v1 and will
+// only change together with the version string.
+func (p *Packet) Fingerprint() (fp [sha256.Size]byte) {
+	if p == nil {
+		return
+	}
+	var tmp [10]byte
+	h := newHash(crypto.SHA256)
+	h.Write([]byte(fingerprintV1))
+	h.Write([]byte{byte(p.code), p.id})
+	var auth [authLen]byte
+	copy(auth[:], p.auth)
+	h.Write(auth[:])
+	for _, a := range p.attrList() {
+		n := len(a.data)
+		if a.ad.IsTagged() {
+			n++
+		}
+		tmp[0] = byte(a.atype)
+		binary.BigEndian.PutUint32(tmp[1:], uint32(a.vid))
+		tmp[5] = byte(a.vtype)
+		binary.BigEndian.PutUint32(tmp[6:], uint32(n))
+		h.Write(tmp[:])
+		if a.vtype > 0xff {
+			h.Write([]byte{byte(a.vtype >> 8)})
+		}
+		if a.ad.IsTagged() {
+			h.Write([]byte{a.tag})
+		}
+		h.Write(a.data)
+	}
+	h.Sum(fp[:0])
+	return
+}
+
+func (p *Packet) FingerprintHex() string {
+	fp := p.Fingerprint()
+	return hex.EncodeToString(fp[:])
+}
+
+// Key for duplicate request detection (RFC 5080 2.2.2): hash of source
+// address and port, code, ID and Request Authenticator. Retransmits of the
+// same request get the same key, use string(key[:]) for Store.
+func (p *Packet) DedupKey(src netip.AddrPort) (key [sha256.Size]byte) {
+	if p == nil {
+		return
+	}
+	var buf [16 + 2 + 2 + authLen]byte // addr, port, code and id, auth
+	a16 := src.Addr().As16()
+	copy(buf[:], a16[:])
+	binary.BigEndian.PutUint16(buf[16:], src.Port())
+	buf[18] = byte(p.code)
+	buf[19] = p.id
+	copy(buf[20:], p.auth)
+	copy(key[:], hashSum(crypto.SHA256, buf[:]))
+	return
+}
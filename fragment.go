@@ -0,0 +1,182 @@
+package radius
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Frag-Status values (RFC 7499)
+const (
+	FragAllowed         uint32 = 1
+	FragMoreDataPending uint32 = 2
+	FragMoreDataRequest uint32 = 3
+)
+
+const (
+	fragStatusLen         = 7   // Frag-Status attr len
+	serviceAddAuth uint32 = 19  // Service-Type Additional-Authorization
+	stateRoom             = 255 // room for State, added to chunks by client
+)
+
+var errFragAttr = errors.New("Attr does not fit in fragment")
+
+func isFragStatus(a *Attr) bool {
+	return a.atype == AttrExtended1 && a.vtype == ExtFragStatus && len(a.data) == 4
+}
+
+// Frag-Status value, false if absent
+func (p *Packet) FragStatus() (uint32, bool) {
+	if p == nil {
+		return 0, false
+	}
+	for _, a := range p.attrList() {
+		if isFragStatus(a) {
+			return binary.BigEndian.Uint32(a.data), true
+		}
+	}
+	return 0, false
+}
+
+func (p *Packet) AddFragStatus(v uint32) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	return p.addAttr(AttrExtended1, 0, ExtFragStatus, stdExtAttr(AttrExtended1, ExtFragStatus), 0, v)
+}
+
+// new chunk of p, chunks after first carry User-Name and Service-Type
+// Additional-Authorization (RFC 7499 4.1)
+func (p *Packet) fragChunk(first bool, un *Attr, max int) (*Packet, int, error) {
+	c := &Packet{
+		code:   p.code,
+		id:     p.id,
+		secret: p.secret,
+		rand:   p.rand,
+		mauth:  p.mauth,
+		maxLen: max,
+	}
+	n := MinPLen + fragStatusLen + msgAuthLen
+	if first {
+		return c, n, nil
+	}
+	n += stateRoom
+	if un != nil {
+		na := *un
+		na.pkt = c
+		c.appendAttr(&na)
+		n += na.wireLen()
+	}
+	if p.code == AccessRequest {
+		if err := c.addStd(AttrServiceType, 0, serviceAddAuth); err != nil {
+			return nil, 0, err
+		}
+		n += 6
+	}
+	return c, n, nil
+}
+
+// Split packet into chunks of at most max bytes (RFC 7499), 0 - packet max len.
+// Packet is returned as is if it fits. Every chunk but last has Frag-Status
+// More-Data-Pending, room is left in chunks after first for State from
+// Access-Challenge, which caller must add along with packet ID.
+// Message-Authenticator is added to every chunk if packet has one.
+func (p *Packet) Fragment(max int) ([]*Packet, error) {
+	if p == nil {
+		return nil, errors.New("Packet empty")
+	}
+	if max <= 0 {
+		max = p.getMaxLen()
+	}
+	attrs := p.attrList()
+	size := MinPLen + p.attrsLen()
+	if p.emitMsgAuth() {
+		size += msgAuthLen
+	}
+	if size <= max {
+		return []*Packet{p}, nil
+	}
+	var un *Attr
+	for _, a := range attrs {
+		if a.atype == AttrUserName {
+			un = a
+			break
+		}
+	}
+	msgAuth := p.mauth || p.HasAttr(AttrMessageAuthenticator)
+	var chunks []*Packet
+	c, n, err := p.fragChunk(true, un, max)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attrs {
+		if a.atype == AttrMessageAuthenticator {
+			continue
+		}
+		if wl := a.wireLen(); n+wl > max {
+			if len(c.attrs) == 0 {
+				return nil, errFragAttr
+			}
+			chunks = append(chunks, c)
+			if c, n, err = p.fragChunk(false, un, max); err != nil {
+				return nil, err
+			}
+			if n+wl > max {
+				return nil, errFragAttr
+			}
+		}
+		na := *a
+		na.pkt = c
+		c.appendAttr(&na)
+		n += na.wireLen()
+	}
+	chunks = append(chunks, c)
+	for i, c := range chunks {
+		if i < len(chunks)-1 {
+			if err = c.AddFragStatus(FragMoreDataPending); err != nil {
+				return nil, err
+			}
+		}
+		if msgAuth {
+			if err = c.AddMsgAuth(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// Join chunks received in order into one packet (RFC 7499). Frag-Status
+// and Message-Authenticator are dropped, as well as User-Name, Service-Type
+// and State of chunks after first. Result may exceed MaxPLen.
+func Reassemble(chunks ...*Packet) (*Packet, error) {
+	if len(chunks) == 0 || chunks[0] == nil {
+		return nil, errors.New("Packet empty")
+	}
+	first := chunks[0]
+	np := &Packet{
+		code:   first.code,
+		id:     first.id,
+		auth:   first.auth,
+		secret: first.secret,
+		rand:   first.rand,
+		mauth:  first.mauth,
+		maxLen: MaxLongLen,
+	}
+	for i, c := range chunks {
+		if c == nil || c.code != first.code {
+			return nil, errors.New("Invalid fragment")
+		}
+		for _, a := range c.attrList() {
+			switch {
+			case isFragStatus(a), a.atype == AttrMessageAuthenticator:
+				continue
+			case i > 0 && (a.atype == AttrUserName || a.atype == AttrServiceType || a.atype == AttrState):
+				continue
+			}
+			na := *a
+			na.pkt = np
+			np.appendAttr(&na)
+		}
+	}
+	return np, nil
+}
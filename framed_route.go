@@ -0,0 +1,124 @@
+package radius
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var errFramedRoute = errors.New("Invalid Framed-Route format")
+
+// Framed-Route (RFC 2865) / Framed-IPv6-Route (RFC 3162) value:
+// "prefix[/len] gateway metric [metric...]"
+type FramedRoute struct {
+	Prefix  *net.IPNet
+	Gateway net.IP // unspecified address - user's address
+	Metrics []int
+}
+
+func ParseFramedRoute(s string) (*FramedRoute, error) {
+	var (
+		ip  net.IP
+		pfx *net.IPNet
+		err error
+	)
+
+	f := strings.Fields(s)
+	if len(f) < 2 {
+		return nil, errFramedRoute
+	}
+	if strings.IndexByte(f[0], '/') >= 0 {
+		if ip, pfx, err = net.ParseCIDR(f[0]); err != nil {
+			return nil, errFramedRoute
+		}
+		if !ip.Equal(pfx.IP) { // host bits set
+			return nil, errFramedRoute
+		}
+	} else {
+		if ip = net.ParseIP(f[0]); ip == nil {
+			return nil, errFramedRoute
+		}
+		pfx = hostNet(ip)
+	}
+	fr := &FramedRoute{Prefix: pfx}
+	if fr.Gateway = net.ParseIP(f[1]); fr.Gateway == nil {
+		return nil, errFramedRoute
+	}
+	if (fr.Gateway.To4() == nil) != (pfx.IP.To4() == nil) {
+		return nil, errFramedRoute
+	}
+	if v4 := fr.Gateway.To4(); v4 != nil {
+		fr.Gateway = v4
+	}
+	for _, m := range f[2:] {
+		v, err := strconv.Atoi(m)
+		if err != nil || v < 0 {
+			return nil, errFramedRoute
+		}
+		fr.Metrics = append(fr.Metrics, v)
+	}
+	return fr, nil
+}
+
+func hostNet(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+func (fr *FramedRoute) IsIPv6() bool {
+	return fr.Prefix != nil && fr.Prefix.IP.To4() == nil
+}
+
+// Formatted value, metric 1 is used if none set
+func (fr *FramedRoute) String() string {
+	if fr.Prefix == nil {
+		return ""
+	}
+	gw := fr.Gateway
+	if gw == nil {
+		gw = net.IPv4zero
+		if fr.IsIPv6() {
+			gw = net.IPv6unspecified
+		}
+	}
+	r := fr.Prefix.String() + " " + gw.String()
+	if len(fr.Metrics) == 0 {
+		return r + " 1"
+	}
+	for _, m := range fr.Metrics {
+		r += " " + strconv.Itoa(m)
+	}
+	return r
+}
+
+// All Framed-Route and Framed-IPv6-Route values
+func (p *Packet) GetFramedRoutes() (r []*FramedRoute, err error) {
+	if p == nil {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.atype != AttrFramedRoute && a.atype != AttrFramedIPv6Route {
+			continue
+		}
+		fr, err := ParseFramedRoute(string(a.data))
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, fr)
+	}
+	return
+}
+
+// Add as Framed-Route or Framed-IPv6-Route by prefix family
+func (p *Packet) AddFramedRoute(fr *FramedRoute) error {
+	if fr.Prefix == nil {
+		return errFramedRoute
+	}
+	if fr.IsIPv6() {
+		return p.addStd(AttrFramedIPv6Route, 0, fr.String())
+	}
+	return p.addStd(AttrFramedRoute, 0, fr.String())
+}
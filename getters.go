@@ -0,0 +1,160 @@
+package radius
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// Attr data from dictionary, builtin data for standard attrs
+func (a *Attr) attrData() *AttrData {
+	switch {
+	case a.ad != nil, a.IsVSA():
+		return a.ad
+	case a.IsExtended():
+		return rfcExtAttrs[attrKey(a.atype, 0, a.vtype)]
+	}
+	return rfcAttrs[a.atype]
+}
+
+// first attr of type with plain data of dtype, nil if absent or encrypted
+func (p *Packet) typedAttr(at AttrType, dts ...AttrDType) (*Attr, AttrDType) {
+	a := p.GetAttr(at)
+	if a == nil {
+		return nil, 0
+	}
+	ad := a.attrData()
+	if ad == nil || ad.enc != AttrEncNone {
+		return nil, 0
+	}
+	for _, dt := range dts {
+		if ad.dtype == dt {
+			return a, dt
+		}
+	}
+	return nil, 0
+}
+
+func (p *Packet) GetString(at AttrType) (string, bool) {
+	a, _ := p.typedAttr(at, DTypeString)
+	if a == nil {
+		return "", false
+	}
+	return string(a.data), true
+}
+
+func (p *Packet) GetUint32(at AttrType) (uint32, bool) {
+	a, _ := p.typedAttr(at, DTypeInt)
+	if a == nil {
+		return 0, false
+	}
+	return intData(a.attrData(), a.data)
+}
+
+// IPv4 or IPv6 address
+func (p *Packet) GetIP(at AttrType) (net.IP, bool) {
+	a, dt := p.typedAttr(at, DTypeIP4, DTypeIP6)
+	switch {
+	case a == nil:
+		return nil, false
+	case dt == DTypeIP4 && len(a.data) != 4, dt == DTypeIP6 && len(a.data) != 16:
+		return nil, false
+	}
+	return net.IP(a.data), true
+}
+
+// Unix or SNTP time
+func (p *Packet) GetTime(at AttrType) (time.Time, bool) {
+	a, dt := p.typedAttr(at, DTypeDate, DTypeSNTP)
+	if a == nil || len(a.data) != 4 {
+		return time.Time{}, false
+	}
+	v := binary.BigEndian.Uint32(a.data)
+	if dt == DTypeSNTP {
+		return ntpTime(v), true
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// IPv4 or IPv6 prefix
+func (p *Packet) GetPrefix(at AttrType) (*net.IPNet, bool) {
+	a, dt := p.typedAttr(at, DTypeIP4Pfx, DTypeIP6Pfx)
+	if a == nil {
+		return nil, false
+	}
+	return decodePrefix(a.data, dt == DTypeIP6Pfx)
+}
+
+// IPv4 or IPv6 address as netip.Addr
+func (p *Packet) GetAddr(at AttrType) (netip.Addr, bool) {
+	ip, ok := p.GetIP(at)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	return ipAddr(ip)
+}
+
+// IPv4 or IPv6 prefix as netip.Prefix
+func (p *Packet) GetIPPrefix(at AttrType) (netip.Prefix, bool) {
+	n, ok := p.GetPrefix(at)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	return netPrefix(n)
+}
+
+// IPv4 is 4 bytes in attrs, so it is not mapped to IPv6
+func ipAddr(ip net.IP) (netip.Addr, bool) {
+	return netip.AddrFromSlice(ip)
+}
+
+func netPrefix(n *net.IPNet) (netip.Prefix, bool) {
+	addr, ok := ipAddr(n.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ones, _ := n.Mask.Size()
+	return netip.PrefixFrom(addr, ones), true
+}
+
+func (p *Packet) GetMAC(at AttrType) (net.HardwareAddr, bool) {
+	a, _ := p.typedAttr(at, DTypeEth)
+	if a == nil || len(a.data) != 6 {
+		return nil, false
+	}
+	return net.HardwareAddr(a.data), true
+}
+
+// Value of first attr by name, T is decoded type of attr data (string,
+// uint32, net.IP, time.Time, ...) or []byte for raw value. Addresses and
+// prefixes are available as netip.Addr and netip.Prefix as well.
+func Get[T any](p *Packet, name string) (v T, ok bool) {
+	a := p.GetAttrByName(name)
+	if a == nil {
+		return
+	}
+	var ed interface{}
+	if a.ad != nil {
+		ed = a.GetEData()
+	} else {
+		ed = decodeData(a.attrData(), a.data)
+	}
+	if v, ok = ed.(T); ok {
+		return
+	}
+	switch t := interface{}(&v).(type) {
+	case *netip.Addr:
+		if ip, isIP := ed.(net.IP); isIP {
+			*t, ok = ipAddr(ip)
+		}
+		return
+	case *netip.Prefix:
+		if n, isNet := ed.(*net.IPNet); isNet {
+			*t, ok = netPrefix(n)
+		}
+		return
+	}
+	v, ok = interface{}(a.data).(T)
+	return
+}
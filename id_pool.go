@@ -0,0 +1,152 @@
+package radius
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrIDExhausted = errors.New("All packet IDs in use")
+	ErrIDQueueFull = errors.New("Too many callers wait for packet ID")
+)
+
+// Behavior of IDPool.Acquire when all 256 IDs are outstanding
+type IDPolicy int
+
+const (
+	IDFail  IDPolicy = iota // return ErrIDExhausted
+	IDBlock                 // wait until ID is released or ctx is done
+	IDQueue                 // wait as IDBlock, ErrIDQueueFull if IDPool.Queue callers wait already
+)
+
+// Allocator of packet IDs, use one pool per destination/socket.
+// ID is outstanding from Get until Release on response or timeout.
+// Zero value is ready to use.
+type IDPool struct {
+	Policy IDPolicy      // Acquire behavior when all IDs are outstanding
+	Queue  int           // max waiting callers with IDQueue policy
+	mu     sync.Mutex    // guards pool state
+	used   [4]uint64     // bitmap of outstanding IDs
+	n      int           // outstanding IDs count
+	next   byte          // next ID to try, IDs are handed out round-robin
+	wake   chan struct{} // closed on Release to wake waiters, nil - no waiters
+	nwait  int           // callers waiting in Wait
+}
+
+func NewIDPool() *IDPool {
+	return &IDPool{}
+}
+
+func (ip *IDPool) get() (byte, bool) {
+	if ip.n == 256 {
+		return 0, false
+	}
+	for {
+		id := ip.next
+		ip.next++
+		if ip.used[id>>6]&(1<<(id&63)) == 0 {
+			ip.used[id>>6] |= 1 << (id & 63)
+			ip.n++
+			return id, true
+		}
+	}
+}
+
+// Get free ID, ErrIDExhausted if all 256 are outstanding
+func (ip *IDPool) Get() (byte, error) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	id, ok := ip.get()
+	if !ok {
+		return 0, ErrIDExhausted
+	}
+	return id, nil
+}
+
+// Get free ID, block until one is released or ctx is done
+func (ip *IDPool) Wait(ctx context.Context) (byte, error) {
+	return ip.wait(ctx, -1)
+}
+
+// Get free ID as pool Policy says
+func (ip *IDPool) Acquire(ctx context.Context) (byte, error) {
+	switch ip.Policy {
+	case IDBlock:
+		return ip.wait(ctx, -1)
+	case IDQueue:
+		return ip.wait(ctx, ip.Queue)
+	}
+	return ip.Get()
+}
+
+// wait for free ID, queue < 0 - waiters are not limited
+func (ip *IDPool) wait(ctx context.Context, queue int) (byte, error) {
+	ip.mu.Lock()
+	if id, ok := ip.get(); ok {
+		ip.mu.Unlock()
+		return id, nil
+	}
+	if queue >= 0 && ip.nwait >= queue {
+		ip.mu.Unlock()
+		return 0, ErrIDQueueFull
+	}
+	ip.nwait++
+	defer func() {
+		ip.mu.Lock()
+		ip.nwait--
+		ip.mu.Unlock()
+	}()
+	for {
+		if ip.wake == nil {
+			ip.wake = make(chan struct{})
+		}
+		wake := ip.wake
+		ip.mu.Unlock()
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+		ip.mu.Lock()
+		if id, ok := ip.get(); ok {
+			ip.mu.Unlock()
+			return id, nil
+		}
+	}
+}
+
+// Return ID to pool, releasing free ID does nothing
+func (ip *IDPool) Release(id byte) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	if ip.used[id>>6]&(1<<(id&63)) == 0 {
+		return
+	}
+	ip.used[id>>6] &^= 1 << (id & 63)
+	ip.n--
+	if ip.wake != nil {
+		close(ip.wake)
+		ip.wake = nil
+	}
+}
+
+// Number of outstanding IDs
+func (ip *IDPool) Len() int {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	return ip.n
+}
+
+// Set packet ID from pool, see Acquire
+func (ip *IDPool) Assign(ctx context.Context, p *Packet) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	id, err := ip.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	p.id = id
+	return nil
+}
@@ -0,0 +1,64 @@
+package radius
+
+import (
+	"encoding/binary"
+)
+
+// Check attr boundaries and fill presence bitmap without building attrs.
+// HasAttr works on scanned packet, anything else builds attrs first.
+func (p *Packet) scan(buf []byte) error {
+	rb := rBuf{buf: buf, bl: len(buf)}
+	cont := false // previous long extended attr has More flag
+	for rb.getLeft() > 0 {
+		at, ad, err := rb.getAttr()
+		if err != nil {
+			if p.perm { // load records bad attr
+				break
+			}
+			return err
+		}
+		switch {
+		case AttrType(at) == AttrVSA:
+			err = checkVSA(ad)
+		case isExtended(AttrType(at)):
+			cont, err = checkExtEVS(AttrType(at), ad, cont)
+		default:
+			cont = false
+		}
+		if err != nil {
+			if !p.perm {
+				return err
+			}
+			continue // permissive load records bad attr
+		}
+		p.amap.set(AttrType(at))
+	}
+	p.lazy = true
+	return nil
+}
+
+// build attrs of lazy parsed packet, data was checked by scan
+func (p *Packet) load() {
+	if !p.lazy {
+		return
+	}
+	p.lazy = false
+	pl := int(binary.BigEndian.Uint16(p.data[2:]))
+	p.lerr = p.parseAttrs(p.data[MinPLen:pl])
+}
+
+// Error of building attrs of lazy parsed packet, attrs after malformed one
+// are missing if not nil. Attrs are built if not yet.
+func (p *Packet) LoadErr() error {
+	if p == nil {
+		return nil
+	}
+	p.load()
+	return p.lerr
+}
+
+// attrs of packet, built on first access for lazy parsed packets
+func (p *Packet) attrList() []*Attr {
+	p.load()
+	return p.attrs
+}
@@ -0,0 +1,85 @@
+package radius
+
+// First attr of type, nil if absent
+func (p *Packet) GetAttr(at AttrType) *Attr {
+	if p == nil || !p.HasAttr(at) {
+		return nil
+	}
+	for _, a := range p.attrList() {
+		if a.atype == at {
+			return a
+		}
+	}
+	return nil
+}
+
+// All attrs of type in packet order, for multi-valued attrs
+func (p *Packet) GetAttrs(at AttrType) (r []*Attr) {
+	if p == nil || !p.HasAttr(at) {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.atype == at {
+			r = append(r, a)
+		}
+	}
+	return
+}
+
+// First VSA of vendor and type, nil if absent
+func (p *Packet) GetVSA(vid VendorID, vtype VendorType) *Attr {
+	if p == nil || !p.HasVendor(vid) {
+		return nil
+	}
+	for _, a := range p.attrList() {
+		if a.IsVSA() && a.vid == vid && a.vtype == vtype {
+			return a
+		}
+	}
+	return nil
+}
+
+// All VSAs of vendor and type in packet order
+func (p *Packet) GetVSAs(vid VendorID, vtype VendorType) (r []*Attr) {
+	if p == nil || !p.HasVendor(vid) {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.IsVSA() && a.vid == vid && a.vtype == vtype {
+			r = append(r, a)
+		}
+	}
+	return
+}
+
+// First attr by dictionary name, standard attrs are known without dictionary
+func (p *Packet) GetAttrByName(name string) *Attr {
+	ad := stdAttrByName(name)
+	switch {
+	case ad == nil:
+		return nil
+	case ad.atype == AttrVSA:
+		return p.GetVSA(ad.vid, ad.vtype)
+	case isExtended(ad.atype) && ad.vid != 0:
+		return p.GetEVS(ad.atype, ad.vid, ad.vtype)
+	case isExtended(ad.atype):
+		return p.GetExt(ad.atype, ad.vtype)
+	}
+	return p.GetAttr(ad.atype)
+}
+
+// All attrs by dictionary name in packet order
+func (p *Packet) GetAttrsByName(name string) []*Attr {
+	ad := stdAttrByName(name)
+	switch {
+	case ad == nil:
+		return nil
+	case ad.atype == AttrVSA:
+		return p.GetVSAs(ad.vid, ad.vtype)
+	case isExtended(ad.atype) && ad.vid != 0:
+		return p.GetEVSs(ad.atype, ad.vid, ad.vtype)
+	case isExtended(ad.atype):
+		return p.GetExts(ad.atype, ad.vtype)
+	}
+	return p.GetAttrs(ad.atype)
+}
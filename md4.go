@@ -0,0 +1,69 @@
+package radius
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// MD4 (RFC 1320) for NT password hash, not in standard library
+
+var md4Shift = [3][4]int{
+	{3, 7, 11, 19},
+	{3, 5, 9, 13},
+	{3, 9, 11, 15},
+}
+
+var md4Order2 = [16]int{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+
+var md4Order3 = [16]int{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+
+func md4Block(s *[4]uint32, b []byte) {
+	var x [16]uint32
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	a, bb, c, d := s[0], s[1], s[2], s[3]
+	for i := 0; i < 16; i++ {
+		f := bb&c | ^bb&d
+		a = bits.RotateLeft32(a+f+x[i], md4Shift[0][i%4])
+		a, bb, c, d = d, a, bb, c
+	}
+	for i := 0; i < 16; i++ {
+		g := bb&c | bb&d | c&d
+		a = bits.RotateLeft32(a+g+x[md4Order2[i]]+0x5a827999, md4Shift[1][i%4])
+		a, bb, c, d = d, a, bb, c
+	}
+	for i := 0; i < 16; i++ {
+		h := bb ^ c ^ d
+		a = bits.RotateLeft32(a+h+x[md4Order3[i]]+0x6ed9eba1, md4Shift[2][i%4])
+		a, bb, c, d = d, a, bb, c
+	}
+	s[0] += a
+	s[1] += bb
+	s[2] += c
+	s[3] += d
+}
+
+func md4Sum(b []byte) []byte {
+	s := [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+	n := len(b)
+	for ; len(b) >= 64; b = b[64:] {
+		md4Block(&s, b)
+	}
+	var pad [128]byte
+	k := copy(pad[:], b)
+	pad[k] = 0x80
+	pl := 64
+	if k >= 56 {
+		pl = 128
+	}
+	binary.LittleEndian.PutUint64(pad[pl-8:], uint64(n)<<3)
+	for i := 0; i < pl; i += 64 {
+		md4Block(&s, pad[i:i+64])
+	}
+	r := make([]byte, 16)
+	for i, v := range s {
+		binary.LittleEndian.PutUint32(r[i*4:], v)
+	}
+	return r
+}
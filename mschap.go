@@ -0,0 +1,329 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/des"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+// Microsoft VSAs (RFC 2548)
+const (
+	VendorMicrosoft VendorID   = 311
+	MSCHAPResponse  VendorType = 1
+	MSCHAPError     VendorType = 2
+	MSCHAPChallenge VendorType = 11
+	MSCHAPMPPEKeys  VendorType = 12
+	MSMPPESendKey   VendorType = 16
+	MSMPPERecvKey   VendorType = 17
+	MSCHAP2Response VendorType = 25
+	MSCHAP2Success  VendorType = 26
+)
+
+const (
+	msCHAPRespLen        = 50 // MS-CHAP-Response and MS-CHAP2-Response len
+	msCHAPv1ChallengeLen = 8
+	msCHAPv2ChallengeLen = 16
+)
+
+func msVSA(name string, vtype VendorType, enc AttrEnc) *AttrData {
+	return &AttrData{name: name, atype: AttrVSA, vid: VendorMicrosoft, vtype: vtype, dtype: DTypeRaw, enc: enc}
+}
+
+// Builtin data for Microsoft VSAs, used when VSA is not in dictionary
+var rfcVSAs = map[uint64]*AttrData{
+	attrKey(AttrVSA, VendorMicrosoft, MSCHAPResponse):  msVSA("MS-CHAP-Response", MSCHAPResponse, AttrEncNone),
+	attrKey(AttrVSA, VendorMicrosoft, MSCHAPError):     msVSA("MS-CHAP-Error", MSCHAPError, AttrEncNone),
+	attrKey(AttrVSA, VendorMicrosoft, MSCHAPChallenge): msVSA("MS-CHAP-Challenge", MSCHAPChallenge, AttrEncNone),
+	attrKey(AttrVSA, VendorMicrosoft, MSMPPESendKey):   msVSA("MS-MPPE-Send-Key", MSMPPESendKey, AttrEncTun),
+	attrKey(AttrVSA, VendorMicrosoft, MSMPPERecvKey):   msVSA("MS-MPPE-Recv-Key", MSMPPERecvKey, AttrEncTun),
+	attrKey(AttrVSA, VendorMicrosoft, MSCHAP2Response): msVSA("MS-CHAP2-Response", MSCHAP2Response, AttrEncNone),
+	attrKey(AttrVSA, VendorMicrosoft, MSCHAP2Success):  msVSA("MS-CHAP2-Success", MSCHAP2Success, AttrEncNone),
+}
+
+var (
+	errNoMSCHAP  = errors.New("MS-CHAP attrs missing")
+	errBadMSCHAP = errors.New("MS-CHAP response mismatch")
+)
+
+var (
+	msMagic1 = []byte("Magic server to client signing constant")
+	msMagic2 = []byte("Pad to make it do more than one iteration")
+
+	mppeMasterMagic = []byte("This is the MPPE Master Key")
+	mppeClientSend  = []byte("On the client side, this is the send key; on the server side, it is the receive key.")
+	mppeClientRecv  = []byte("On the client side, this is the receive key; on the server side, it is the send key.")
+	mppePad1        = make([]byte, 40)
+	mppePad2        = []byte(strings.Repeat("\xf2", 40))
+)
+
+// Result of verified MS-CHAP response, keys are MPPE keys of server side
+type MSCHAPResult struct {
+	Ident        byte   // Ident of response
+	AuthResponse string // MS-CHAPv2 "S=..." authenticator response, empty for v1
+	SendKey      []byte // MS-MPPE-Send-Key value
+	RecvKey      []byte // MS-MPPE-Recv-Key value
+}
+
+// MD4 of UTF-16LE password (RFC 2759 8.3)
+func ntPasswordHash(password string) []byte {
+	u := utf16.Encode([]rune(password))
+	b := make([]byte, len(u)*2)
+	for i, c := range u {
+		b[i*2], b[i*2+1] = byte(c), byte(c>>8)
+	}
+	return md4Sum(b)
+}
+
+// 7 bytes key to DES key with parity bit space
+func desKey(k []byte) []byte {
+	return []byte{
+		k[0] & 0xfe,
+		k[0]<<7 | k[1]>>1,
+		k[1]<<6 | k[2]>>2,
+		k[2]<<5 | k[3]>>3,
+		k[3]<<4 | k[4]>>4,
+		k[4]<<3 | k[5]>>5,
+		k[5]<<2 | k[6]>>6,
+		k[6] << 1,
+	}
+}
+
+// DES of 8 bytes challenge with three keys of zero padded hash (RFC 2759 8.5)
+func challengeResponse(challenge, hash []byte) []byte {
+	var zh [21]byte
+	copy(zh[:], hash)
+	r := make([]byte, 24)
+	for i := 0; i < 3; i++ {
+		c, _ := des.NewCipher(desKey(zh[i*7:]))
+		c.Encrypt(r[i*8:], challenge)
+	}
+	return r
+}
+
+// user name without domain
+func msUserName(name string) string {
+	if i := strings.LastIndexByte(name, '\\'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// SHA1(peer challenge + authenticator challenge + user name)[:8] (RFC 2759 8.2)
+func challengeHash(peer, auth []byte, user string) []byte {
+	h := newHash(crypto.SHA1)
+	h.Write(peer)
+	h.Write(auth)
+	h.Write([]byte(msUserName(user)))
+	return h.Sum(nil)[:8]
+}
+
+// MS-CHAPv2 NT-Response (RFC 2759 8.1)
+func ntResponseV2(auth, peer []byte, user, password string) []byte {
+	return challengeResponse(challengeHash(peer, auth, user), ntPasswordHash(password))
+}
+
+// "S=" authenticator response (RFC 2759 8.7)
+func authResponse(password string, ntResp, peer, auth []byte, user string) string {
+	hh := md4Sum(ntPasswordHash(password))
+	h := newHash(crypto.SHA1)
+	h.Write(hh)
+	h.Write(ntResp)
+	h.Write(msMagic1)
+	d := h.Sum(nil)
+	h.Reset()
+	h.Write(d)
+	h.Write(challengeHash(peer, auth, user))
+	h.Write(msMagic2)
+	return "S=" + strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+// MPPE master key of MS-CHAPv2 (RFC 3079 3.4)
+func mppeMasterKey(password string, ntResp []byte) []byte {
+	h := newHash(crypto.SHA1)
+	h.Write(md4Sum(ntPasswordHash(password)))
+	h.Write(ntResp)
+	h.Write(mppeMasterMagic)
+	return h.Sum(nil)[:16]
+}
+
+// 128 bit asymmetric start key (RFC 3079 3.4)
+func mppeStartKey(master, magic []byte) []byte {
+	h := newHash(crypto.SHA1)
+	h.Write(master)
+	h.Write(mppePad1)
+	h.Write(magic)
+	h.Write(mppePad2)
+	return h.Sum(nil)[:16]
+}
+
+// 128 bit start key of MS-CHAPv1, same for both directions (RFC 3079 2.4)
+func mppeKeyV1(password string, challenge []byte) []byte {
+	hh := md4Sum(ntPasswordHash(password))
+	h := newHash(crypto.SHA1)
+	h.Write(hh)
+	h.Write(hh)
+	h.Write(challenge)
+	return h.Sum(nil)[:16]
+}
+
+// add VSA with raw value, dictionary or builtin type is used if known
+func (p *Packet) addVSARaw(vid VendorID, vtype VendorType, v []byte) error {
+	return p.addAttr(AttrVSA, vid, vtype, stdVSA(vid, vtype), 0, v)
+}
+
+func (p *Packet) randBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if err := p.randRead(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Add MS-CHAP-Challenge and MS-CHAP-Response (RFC 2433) with random
+// challenge and ident, NT response only
+func (p *Packet) AddMSCHAPv1(password string) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	ch, err := p.randBytes(msCHAPv1ChallengeLen)
+	if err != nil {
+		return err
+	}
+	ident, err := p.randBytes(1)
+	if err != nil {
+		return err
+	}
+	r := make([]byte, 0, msCHAPRespLen)
+	r = append(r, ident[0], 1) // Flags 1 - use NT response
+	r = append(r, make([]byte, 24)...)
+	r = append(r, challengeResponse(ch, ntPasswordHash(password))...)
+	if err = p.addVSARaw(VendorMicrosoft, MSCHAPChallenge, ch); err != nil {
+		return err
+	}
+	return p.addVSARaw(VendorMicrosoft, MSCHAPResponse, r)
+}
+
+// Add MS-CHAP-Challenge and MS-CHAP2-Response (RFC 2759) with random
+// challenges and ident, User-Name must be set
+func (p *Packet) AddMSCHAPv2(password string) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	user, ok := p.GetUserName()
+	if !ok {
+		return errors.New("User-Name missing")
+	}
+	auth, err := p.randBytes(msCHAPv2ChallengeLen)
+	if err != nil {
+		return err
+	}
+	b, err := p.randBytes(msCHAPv2ChallengeLen + 1)
+	if err != nil {
+		return err
+	}
+	ident, peer := b[0], b[1:]
+	r := make([]byte, 0, msCHAPRespLen)
+	r = append(r, ident, 0)
+	r = append(r, peer...)
+	r = append(r, make([]byte, 8)...)
+	r = append(r, ntResponseV2(auth, peer, user, password)...)
+	if err = p.addVSARaw(VendorMicrosoft, MSCHAPChallenge, auth); err != nil {
+		return err
+	}
+	return p.addVSARaw(VendorMicrosoft, MSCHAP2Response, r)
+}
+
+// Verify MS-CHAP-Response of request against clear text password
+func (p *Packet) VerifyMSCHAPv1(password string) (*MSCHAPResult, error) {
+	if p == nil {
+		return nil, errors.New("Packet empty")
+	}
+	ch, ok := p.getVSAData(VendorMicrosoft, MSCHAPChallenge)
+	r, ok2 := p.getVSAData(VendorMicrosoft, MSCHAPResponse)
+	switch {
+	case !ok || !ok2:
+		return nil, errNoMSCHAP
+	case len(ch) != msCHAPv1ChallengeLen || len(r) != msCHAPRespLen:
+		return nil, errors.New("Invalid MS-CHAP attrs")
+	case r[1]&1 == 0:
+		return nil, errors.New("MS-CHAP LM response not supported")
+	}
+	nt := challengeResponse(ch, ntPasswordHash(password))
+	if subtle.ConstantTimeCompare(nt, r[26:]) != 1 {
+		return nil, errBadMSCHAP
+	}
+	key := mppeKeyV1(password, ch)
+	return &MSCHAPResult{Ident: r[0], SendKey: key, RecvKey: key}, nil
+}
+
+// Verify MS-CHAP2-Response of request against clear text password,
+// result has authenticator response for MS-CHAP2-Success and MPPE keys
+func (p *Packet) VerifyMSCHAPv2(password string) (*MSCHAPResult, error) {
+	if p == nil {
+		return nil, errors.New("Packet empty")
+	}
+	user, _ := p.GetUserName()
+	auth, ok := p.getVSAData(VendorMicrosoft, MSCHAPChallenge)
+	r, ok2 := p.getVSAData(VendorMicrosoft, MSCHAP2Response)
+	switch {
+	case !ok || !ok2:
+		return nil, errNoMSCHAP
+	case len(auth) != msCHAPv2ChallengeLen || len(r) != msCHAPRespLen:
+		return nil, errors.New("Invalid MS-CHAPv2 attrs")
+	}
+	peer, nt := r[2:18], r[26:]
+	if subtle.ConstantTimeCompare(ntResponseV2(auth, peer, user, password), nt) != 1 {
+		return nil, errBadMSCHAP
+	}
+	mk := mppeMasterKey(password, nt)
+	return &MSCHAPResult{
+		Ident:        r[0],
+		AuthResponse: authResponse(password, nt, peer, auth, user),
+		SendKey:      mppeStartKey(mk, mppeClientRecv),
+		RecvKey:      mppeStartKey(mk, mppeClientSend),
+	}, nil
+}
+
+// Add MS-CHAP2-Success with authenticator response to reply
+func (p *Packet) AddMSCHAP2Success(r *MSCHAPResult) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if r == nil || r.AuthResponse == "" {
+		return errors.New("MS-CHAPv2 result empty")
+	}
+	v := append([]byte{r.Ident}, r.AuthResponse...)
+	return p.addVSARaw(VendorMicrosoft, MSCHAP2Success, v)
+}
+
+// Add MS-MPPE-Send-Key and MS-MPPE-Recv-Key, keys are encrypted with salt
+// (RFC 2548 2.4.2) on serialize like Tunnel-Password
+func (p *Packet) AddMPPEKeys(send, recv []byte) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if err := p.addVSARaw(VendorMicrosoft, MSMPPESendKey, send); err != nil {
+		return err
+	}
+	return p.addVSARaw(VendorMicrosoft, MSMPPERecvKey, recv)
+}
+
+// Decrypted MS-MPPE-Send-Key and MS-MPPE-Recv-Key, parsed reply needs
+// request Authenticator, see Attr.Decrypt
+func (p *Packet) GetMPPEKeys() (send, recv []byte, err error) {
+	sa, ra := p.GetVSA(VendorMicrosoft, MSMPPESendKey), p.GetVSA(VendorMicrosoft, MSMPPERecvKey)
+	if sa == nil || ra == nil {
+		return nil, nil, errors.New("MS-MPPE keys missing")
+	}
+	if send, err = sa.Decrypt(); err != nil {
+		return nil, nil, err
+	}
+	if recv, err = ra.Decrypt(); err != nil {
+		return nil, nil, err
+	}
+	return
+}
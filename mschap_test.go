@@ -0,0 +1,66 @@
+package radius
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RFC 1320 A.5 test suite
+func TestMD4(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(md4Sum([]byte(tt.in))); got != tt.want {
+			t.Errorf("md4Sum(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// sample values of RFC 2759 9.2 and RFC 3079 3.5.3
+func TestMSCHAPv2Vectors(t *testing.T) {
+	const (
+		user     = "User"
+		password = "clientPass"
+	)
+	auth := unhex("5b5d7c7d7b3f2f3e3c2c602132262628")
+	peer := unhex("21402324255e262a28295f2b3a337c7e")
+	ntResp := ntResponseV2(auth, peer, user, password)
+	master := mppeMasterKey(password, ntResp)
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"ChallengeHash", challengeHash(peer, auth, user), "d02e4386bce91226"},
+		{"NtPasswordHash", ntPasswordHash(password), "44ebba8d5312b8d611474411f56989ae"},
+		{"PasswordHashHash", md4Sum(ntPasswordHash(password)), "41c00c584bd2d91c4017a2a12fa59f3f"},
+		{"NT-Response", ntResp, "82309ecd8d708b5ea08faa3981cd83544233114a3d85d6df"},
+		{"AuthenticatorResponse", []byte(authResponse(password, ntResp, peer, auth, user)), hex.EncodeToString([]byte("S=407A5589115FD0D6209F510FE9C04566932CDA56"))},
+		{"MasterKey", master, "fdece3717a8c838cb388e527ae3cdd31"},
+		{"SendStartKey128", mppeStartKey(master, mppeClientRecv), "8b7cdc149b993a1ba118cb153f56dccb"}, // server side
+	}
+	for _, tt := range tests {
+		if want := unhex(tt.want); !bytes.Equal(tt.got, want) {
+			t.Errorf("%s = %x, want %x", tt.name, tt.got, want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package radius
+
+import "time"
+
+// SNTP time (RFC 4330): seconds since 1900-01-01 with MSB set,
+// seconds since 2036-02-07 06:28:16 with MSB clear
+const (
+	ntpEra0 = -2208988800 // 1900-01-01 in unix time
+	ntpEra1 = ntpEra0 + 1<<32
+)
+
+func ntpTime(t uint32) time.Time {
+	if t&0x80000000 != 0 {
+		return time.Unix(ntpEra0+int64(t), 0)
+	}
+	return time.Unix(ntpEra1+int64(t), 0)
+}
+
+func ntpSeconds(t time.Time) (uint32, bool) {
+	u := t.Unix()
+	switch {
+	case u >= ntpEra0+0x80000000 && u < ntpEra1:
+		return uint32(u - ntpEra0), true
+	case u >= ntpEra1 && u < ntpEra1+0x80000000:
+		return uint32(u - ntpEra1), true
+	}
+	return 0, false
+}
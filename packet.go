@@ -4,7 +4,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
+	"net/netip"
 	"time"
 )
 
@@ -36,21 +39,84 @@ const (
 )
 
 const (
-	MinPLen = 20   // Min packet len
-	MaxPLen = 4096 // Max packet len
+	MinPLen    = 20    // Min packet len
+	MaxPLen    = 4096  // Max packet len (UDP)
+	MaxLongLen = 65535 // Max packet len for TCP/TLS (RFC 7930)
 )
 
+// Options for ParsePacketOpts
+type ParseOptions struct {
+	MaxLen         int  // Max packet len, 0 - MaxPLen, up to MaxLongLen
+	RequireMsgAuth bool // Reject Access-* packets without Message-Authenticator
+	Lazy           bool // Only check attr boundaries, attrs are built on first access, see Packet.LoadErr
+	Exact          bool // Keep wire form of attrs and padding for byte-exact Serialize
+	Permissive     bool // Skip malformed attrs, see Packet.BadAttrs
+	Strict         bool // Reject octets after packet len and attrs breaking dictionary limits, Lazy attrs are built on parse
+	NoDecrypt      bool // Keep encrypted values in Attr.GetEData, see Packet.SetDecrypt
+}
+
+func (po *ParseOptions) exact() bool {
+	return po != nil && po.Exact
+}
+
+func (po *ParseOptions) permissive() bool {
+	return po != nil && po.Permissive
+}
+
+func (po *ParseOptions) strict() bool {
+	return po != nil && po.Strict
+}
+
+func (po *ParseOptions) lazy() bool {
+	return po != nil && po.Lazy
+}
+
+func (po *ParseOptions) noDecrypt() bool {
+	return po != nil && po.NoDecrypt
+}
+
+func (po *ParseOptions) requireMsgAuth() bool {
+	return po != nil && po.RequireMsgAuth
+}
+
+func (po *ParseOptions) maxLen() int {
+	if po == nil || po.MaxLen <= 0 {
+		return MaxPLen
+	}
+	if po.MaxLen > MaxLongLen {
+		return MaxLongLen
+	}
+	return po.MaxLen
+}
+
 type Packet struct {
 	code   RadiusCode  // Radius packet code
 	id     byte        // Packet ID
 	len    uint16      // Packet len
 	auth   []byte      // Auth data
+	rauth  []byte      // Request Authenticator of parsed reply
 	attrs  []*Attr     // Attr slice
 	vids   []VendorID  // Vendor IDs form packet
+	amap   attrMap     // Attr types present in packet
+	vset   vendorSet   // Vendor IDs present in packet
 	secret []byte      // Radius shared secret
+	secs   [][]byte    // Candidate secrets, see SetSecrets
 	data   []byte      // Raw packet data
+	rand   io.Reader   // Random source, nil - crypto provider
+	mauth  bool        // Message-Authenticator required and emitted first
 	udata  interface{} // User data
+	tdata  typedData   // Typed user data
 	reply  bool        // Is this reply
+	lazy   bool        // Attrs are not built yet, see ParseOptions.Lazy
+	lerr   error       // Error of building lazy parsed attrs, see LoadErr
+	exact  bool        // Keep wire form of parsed attrs, see ParseOptions.Exact
+	pad    []byte      // Octets after packet len, kept in exact mode
+	maxLen int         // Max packet len on serialize, 0 - MaxPLen
+	perm   bool        // Skip malformed attrs, see ParseOptions.Permissive
+	bad    []BadAttr   // Malformed attrs skipped in permissive mode
+	pack   bool        // Pack VSAs of the same vendor on serialize
+	show   bool        // Show secret attr values in String
+	nodec  bool        // Keep encrypted values in Attr.GetEData
 }
 
 func (rc RadiusCode) String() string {
@@ -99,116 +165,213 @@ func (rc RadiusCode) String() string {
 }
 
 func ParsePacket(buf []byte) (pkt *Packet, err error) {
-	var (
-		pl   int                   // packet len
-		rb   *rBuf                 // read buffer
-		at   byte                  // attr type
-		ad   []byte                // attr data
-		vid  VendorID              // vendor id
-		vmap map[VendorID]struct{} // map for VSA
-	)
+	return ParsePacketOpts(buf, nil)
+}
+
+var (
+	errPktShort    = errors.New("Packet too short")
+	errPktLen      = errors.New("Packet len error")
+	errPktTrailing = errors.New("Data after packet len")
+)
 
+// check packet header, returns packet len
+func checkHeader(buf []byte, max int) (int, error) {
 	if len(buf) < MinPLen {
-		err = errors.New("Packet too short")
-		return
+		return 0, errPktShort
 	}
-	pl = int(binary.BigEndian.Uint16(buf[2:]))
-	if pl < MinPLen || pl > MaxPLen || pl > len(buf) {
-		err = errors.New("Packet len error")
+	pl := int(binary.BigEndian.Uint16(buf[2:]))
+	if pl < MinPLen || pl > max || pl > len(buf) {
+		return 0, errPktLen
+	}
+	return pl, nil
+}
+
+func ParsePacketOpts(buf []byte, opts *ParseOptions) (pkt *Packet, err error) {
+	var pl int // packet len
+
+	if pl, err = checkHeader(buf, opts.maxLen()); err != nil {
 		return
 	}
-	pkt = &Packet{
-		code: RadiusCode(buf[0]),
-		id:   buf[1],
-		len:  uint16(pl),
-		auth: buf[4:20],
-		data: buf,
+	if opts.strict() && len(buf) > pl {
+		return nil, errPktTrailing
+	}
+	pkt = AcquirePacket()
+	pkt.code = RadiusCode(buf[0])
+	pkt.id = buf[1]
+	pkt.len = uint16(pl)
+	pkt.auth = buf[4:20]
+	pkt.data = buf
+	pkt.mauth = opts.requireMsgAuth()
+	pkt.perm = opts.permissive()
+	pkt.nodec = opts.noDecrypt()
+	if opts != nil && opts.MaxLen > 0 {
+		pkt.maxLen = opts.maxLen()
+	}
+	if pkt.exact = opts.exact(); pkt.exact && len(buf) > pl {
+		pkt.pad = buf[pl:]
 	}
 	if pl == MinPLen {
+		if pkt.mauth && msgAuthCode(pkt.code) {
+			ReleasePacket(pkt)
+			return nil, errNoMsgAuth
+		}
 		return
 	}
-	rb = newBuf(buf[MinPLen:])
-	defer func() {
-		if err != nil && pkt != nil {
-			for _, a := range pkt.attrs {
-				a.pkt = nil // remove any ref to packet data
+	if opts.lazy() {
+		if err = pkt.scan(buf[MinPLen:pl]); err != nil {
+			ReleasePacket(pkt)
+			return nil, err
+		}
+		if opts.strict() { // limits need attrs
+			if err = pkt.LoadErr(); err == nil {
+				err = pkt.checkLimits()
 			}
-			pkt = nil
+			if err != nil {
+				ReleasePacket(pkt)
+				return nil, err
+			}
+		}
+		if pkt.mauth && msgAuthCode(pkt.code) && !pkt.HasAttr(AttrMessageAuthenticator) {
+			ReleasePacket(pkt)
+			return nil, errNoMsgAuth
+		}
+		return
+	}
+	if err = pkt.parseAttrs(buf[MinPLen:pl]); err != nil { // octets beyond packet len are padding
+		ReleasePacket(pkt) // remove any ref to packet data
+		return nil, err
+	}
+	if opts.strict() {
+		if err = pkt.checkLimits(); err != nil {
+			ReleasePacket(pkt)
+			return nil, err
 		}
-	}()
-	vmap = make(map[VendorID]struct{})
-	for rb.getLeft() >= 2 {
+	}
+	if pkt.mauth && msgAuthCode(pkt.code) && !pkt.HasAttr(AttrMessageAuthenticator) {
+		ReleasePacket(pkt)
+		return nil, errNoMsgAuth
+	}
+	return
+}
+
+func (p *Packet) parseAttrs(buf []byte) (err error) {
+	var (
+		rb   *rBuf  // read buffer
+		at   byte   // attr type
+		ad   []byte // attr data
+		pos  int    // attr offset in buf
+		n    int    // attrs count before attr
+		join *Attr  // attr of previous wire attr to be continued
+		jpos int    // offset of first wire attr of join
+		jn   int    // attrs count before jpos
+		jnd  bool   // wire attr continues join
+	)
+
+	rb = newBuf(buf)
+	for rb.getLeft() > 0 {
+		pos, n = rb.bp, len(p.attrs)
 		if at, ad, err = rb.getAttr(); err != nil {
+			if p.perm { // attr len is broken, rest of packet is lost
+				p.addBad(pos, buf[pos:], err)
+				err = nil
+			}
 			return
 		}
-		if AttrType(at) != AttrVSA { // plain attr
-			pkt.parseAttr(AttrType(at), ad)
-		} else { // VSA
-			if vid, err = pkt.parseVSA(ad); err != nil {
+		switch {
+		case AttrType(at) == AttrVSA:
+			join, jnd, err = p.parseVSA(ad, join)
+		case isExtended(AttrType(at)):
+			join, jnd, err = p.parseExt(AttrType(at), ad, join)
+		default: // plain attr
+			if jnd = join != nil && join.atype == AttrType(at); jnd { // next part of concat attr
+				join.joinData(ad)
+			} else if join = p.parseAttr(AttrType(at), ad); !join.isConcat() {
+				join = nil
+			}
+		}
+		if err != nil {
+			if !p.perm {
 				return
 			}
-			vmap[vid] = struct{}{}
+			p.addBad(pos, buf[pos:rb.bp], err)
+			err, join = nil, nil
+			continue
+		}
+		if !jnd {
+			jpos, jn = pos, n
+		}
+		if p.exact { // joined wire attrs are kept together
+			p.setRaw(p.attrs[jn:], buf[jpos:rb.bp])
 		}
-	}
-	pkt.vids = make([]VendorID, 0, len(vmap))
-	for v := range vmap {
-		pkt.vids = append(pkt.vids, v)
 	}
 	return
 }
 
-func (p *Packet) parseAttr(at AttrType, ad []byte) {
+func (p *Packet) parseAttr(at AttrType, ad []byte) *Attr {
 	var attr *Attr // attribute
 
-	attr = &Attr{
+	attr = newAttr(Attr{
 		atype: at,
 		alen:  byte(len(ad) + 2),
-		ad:    GetAttrByAttr(at),
+		ad:    stdAttr(at),
 		pkt:   p,
-	}
-	if attr.ad != nil && attr.ad.IsTagged() {
-		attr.tag = ad[0]
-		attr.data = ad[1:]
-	} else {
-		attr.data = ad
-	}
-	p.attrs = append(p.attrs, attr)
+	})
+	attr.tag, attr.data = splitTag(attr.ad, ad)
+	p.appendAttr(attr)
+	return attr
 }
 
-func (p *Packet) parseVSA(adata []byte) (vid VendorID, err error) {
+// Parse VSA, join is attr with continuation flag from previous VSA.
+// Returns attr to be continued by next VSA and if first sub-attr was joined.
+func (p *Packet) parseVSA(adata []byte, join *Attr) (next *Attr, joined bool, err error) {
 	var (
-		rb   *rBuf  // nested read buffer
-		vt   byte   // vendor type
-		vd   []byte // vendor data
-		attr *Attr  // attribute
+		vid  VendorID   // vendor ID
+		vf   *vendorFmt // VSA header format
+		vt   VendorType // vendor type
+		vd   []byte     // vendor data
+		more bool       // continuation flag
+		rest []byte     // sub-attrs left
+		attr *Attr      // attribute
 	)
 
 	if len(adata) < 6 {
-		err = errors.New("VSA too short")
+		err = errVSAShort
 		return
 	}
 	vid = VendorID(binary.BigEndian.Uint32(adata))
-	rb = newBuf(adata[4:])
-	for rb.getLeft() >= 2 {
-		if vt, vd, err = rb.getAttr(); err != nil {
+	if p.perm { // check all before adding any
+		if err = checkVSA(adata); err != nil {
+			return
+		}
+	}
+	vf = vendorFormat(vid)
+	alen := byte(len(adata) + 2) // wire attr len, shared by packed VSAs
+	for rest = adata[4:]; len(rest) > 0; {
+		if vt, vd, more, rest, err = vf.next(rest); err != nil {
 			return
 		}
-		attr = &Attr{
-			atype: AttrVSA,
-			alen:  byte(len(vd) + 8), // TODO: detect packed VSAs
-			vid:   vid,
-			vtype: VendorType(vt),
-			vlen:  byte(len(vd) + 2),
-			ad:    GetVSAByAttr(vid, VendorType(vt)),
-			pkt:   p,
-		}
-		if attr.ad != nil && attr.ad.IsTagged() {
-			attr.tag = vd[0]
-			attr.data = vd[1:]
+		next = nil
+		if join != nil && join.IsVSA() && join.vid == vid && join.vtype == vt { // continues previous VSA
+			join.joinData(vd)
+			attr, join, joined = join, nil, true
 		} else {
-			attr.data = vd
+			join = nil
+			attr = newAttr(Attr{
+				atype: AttrVSA,
+				alen:  alen,
+				vid:   vid,
+				vtype: vt,
+				vlen:  byte(len(vd) + vf.hdrLen()),
+				vf:    vf,
+				ad:    stdVSA(vid, vt),
+				pkt:   p,
+			})
+			attr.tag, attr.data = splitTag(attr.ad, vd)
+			p.appendAttr(attr)
+		}
+		if more {
+			next = attr
 		}
-		p.attrs = append(p.attrs, attr)
 	}
 	return
 }
@@ -239,6 +402,78 @@ func (p *Packet) SetSecret(secret []byte) {
 		return
 	}
 	p.secret = secret
+	p.dropEData()
+}
+
+func (p *Packet) GetID() byte {
+	if p == nil {
+		return 0
+	}
+	return p.id
+}
+
+func (p *Packet) SetID(id byte) {
+	if p == nil {
+		return
+	}
+	p.id = id
+}
+
+// Set max packet len for Serialize (MaxLongLen for TCP/TLS)
+func (p *Packet) SetMaxLen(n int) {
+	if p == nil {
+		return
+	}
+	if n < MinPLen || n > MaxLongLen {
+		n = MaxPLen
+	}
+	p.maxLen = n
+}
+
+func (p *Packet) getMaxLen() int {
+	if p.maxLen == 0 {
+		return MaxPLen
+	}
+	return p.maxLen
+}
+
+// Authenticator of packet, nil if not set yet. For replies it is
+// Authenticator of request, wire value is calculated on serialize
+func (p *Packet) GetAuth() []byte {
+	if p == nil {
+		return nil
+	}
+	return p.auth
+}
+
+// Set Authenticator, must be 16 bytes. For replies it is Authenticator
+// of request, see GetAuth.
+func (p *Packet) SetAuth(auth []byte) {
+	if p == nil {
+		return
+	}
+	p.auth = auth
+	p.dropEData()
+}
+
+// Packet len on wire as Serialize would write it
+func (p *Packet) Len() int {
+	if p == nil {
+		return 0
+	}
+	n := MinPLen + p.attrsLen()
+	if p.emitMsgAuth() {
+		n += msgAuthLen
+	}
+	return n
+}
+
+// Packet bytes as received, without padding; nil if packet was not parsed
+func (p *Packet) Raw() []byte {
+	if p == nil || p.data == nil {
+		return nil
+	}
+	return p.data[:binary.BigEndian.Uint16(p.data[2:])]
 }
 
 func (p *Packet) GetCode() RadiusCode {
@@ -259,73 +494,166 @@ func (p *Packet) GetVIDs() []VendorID {
 	if p == nil {
 		return nil
 	}
+	p.load()
 	return p.vids
 }
 
-func (p *Packet) AddAttrSimple(attr *Attr) {
+// Add copy of built attr or attr of another packet, attr itself stays
+// owned by caller or its packet. Encrypted attr of another packet is
+// decrypted with its secret and Authenticator, so it is encrypted for this
+// packet on serialize, error if it can't be decrypted.
+func (p *Packet) AddAttrSimple(attr *Attr) error {
 	if p == nil {
-		return
+		return errors.New("Packet empty")
 	}
-	attr.pkt = p
-	p.attrs = append(p.attrs, attr)
+	if attr == nil {
+		return errors.New("Attr empty")
+	}
+	na := newAttr(*attr)
+	na.edata = nil
+	if attr.pkt != nil && attr.pkt != p { // wire form is not valid for p
+		na.raw, na.cont = nil, false
+	}
+	if err := na.reclear(p); err != nil {
+		*na = Attr{}
+		attrPool.Put(na)
+		return err
+	}
+	na.setLen()
+	na.pkt = p
+	p.appendAttr(na)
+	return nil
 }
 
 func attrConv(ad AttrDType, v interface{}) ([]byte, error) {
 	switch ad {
-	case DTypeRaw:
-		av, ok := v.([]byte)
-		if !ok {
-			return nil, errInvalidFormat
+	case DTypeRaw, DTypeVSA:
+		switch av := v.(type) {
+		case []byte:
+			return av, nil
+		case string:
+			return []byte(av), nil
 		}
-		return av, nil
+		return nil, errInvalidFormat
+	case DTypeTLV:
+		switch av := v.(type) {
+		case []TLV:
+			return appendTLVs(nil, av)
+		case []byte:
+			return av, nil
+		}
+		return nil, errInvalidFormat
 	case DTypeString:
-		av, ok := v.(string)
-		if !ok {
-			return nil, errInvalidFormat
+		switch av := v.(type) {
+		case string:
+			return []byte(av), nil
+		case []byte:
+			return av, nil
 		}
-		return []byte(av), nil
+		return nil, errInvalidFormat
 	case DTypeIP4:
-		av, ok := v.(net.IP)
-		if !ok {
-			return nil, errInvalidFormat
+		var av net.IP
+		switch t := v.(type) {
+		case net.IP:
+			av = t.To4()
+		case netip.Addr:
+			if t = t.Unmap(); t.Is4() {
+				av = t.AsSlice()
+			}
 		}
-		if av = av.To4(); av == nil {
+		if av == nil {
 			return nil, errInvalidFormat
 		}
 		return av, nil
+	case DTypeIP4Pfx, DTypeIP6Pfx:
+		switch av := v.(type) {
+		case *net.IPNet:
+			return encodePrefix(av, ad == DTypeIP6Pfx)
+		case net.IPNet:
+			return encodePrefix(&av, ad == DTypeIP6Pfx)
+		case netip.Prefix:
+			if !av.IsValid() {
+				return nil, errInvalidFormat
+			}
+			return encodePrefix(&net.IPNet{
+				IP:   av.Addr().AsSlice(),
+				Mask: net.CIDRMask(av.Bits(), av.Addr().BitLen()),
+			}, ad == DTypeIP6Pfx)
+		}
+		return nil, errInvalidFormat
 	case DTypeInt:
-		av, ok := v.(uint32)
+		av, ok := toUint(v, math.MaxUint32)
 		if !ok {
 			return nil, errInvalidFormat
 		}
 		b := make([]byte, 4)
-		binary.BigEndian.PutUint32(b, av)
+		binary.BigEndian.PutUint32(b, uint32(av))
 		return b, nil
 	case DTypeInt64:
-		av, ok := v.(uint64)
+		av, ok := toUint(v, math.MaxUint64)
 		if !ok {
 			return nil, errInvalidFormat
 		}
 		b := make([]byte, 8)
 		binary.BigEndian.PutUint64(b, av)
 		return b, nil
+	case DTypeSInt:
+		av, ok := toInt(v)
+		if !ok || av < math.MinInt32 || av > math.MaxInt32 {
+			return nil, errInvalidFormat
+		}
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(int32(av)))
+		return b, nil
 	case DTypeDate:
-		switch v.(type) {
+		var t int64
+		switch av := v.(type) {
 		case time.Time:
-			av := v.(time.Time)
-			b := make([]byte, 4)
-			binary.BigEndian.PutUint32(b, uint32(av.Unix()))
-			return b, nil
-		case int64:
-			av := v.(int64)
-			b := make([]byte, 4)
-			binary.BigEndian.PutUint32(b, uint32(av))
-			return b, nil
+			t = av.Unix()
 		default:
+			var ok bool
+			if t, ok = toInt(v); !ok {
+				return nil, errInvalidFormat
+			}
+		}
+		if t < 0 || t > math.MaxUint32 { // unsigned 32 bit seconds
 			return nil, errInvalidFormat
 		}
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(t))
+		return b, nil
+	case DTypeSNTP:
+		var t uint32
+		switch av := v.(type) {
+		case time.Time:
+			var ok bool
+			if t, ok = ntpSeconds(av); !ok {
+				return nil, errInvalidFormat
+			}
+		default:
+			u, ok := toUint(v, math.MaxUint32)
+			if !ok {
+				return nil, errInvalidFormat
+			}
+			t = uint32(u)
+		}
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, t)
+		return b, nil
 	case DTypeIfID:
-		av, ok := v.(uint64)
+		switch av := v.(type) {
+		case []byte:
+			if len(av) != 8 {
+				return nil, errInvalidFormat
+			}
+			return av, nil
+		case net.HardwareAddr: // EUI-64
+			if len(av) != 8 {
+				return nil, errInvalidFormat
+			}
+			return av, nil
+		}
+		av, ok := toUint(v, math.MaxUint64)
 		if !ok {
 			return nil, errInvalidFormat
 		}
@@ -333,119 +661,318 @@ func attrConv(ad AttrDType, v interface{}) ([]byte, error) {
 		binary.BigEndian.PutUint64(b, av)
 		return b, nil
 	case DTypeIP6:
-		av, ok := v.(net.IP)
-		if !ok {
-			return nil, errInvalidFormat
+		var av net.IP
+		switch t := v.(type) {
+		case net.IP:
+			av = t.To16()
+		case netip.Addr:
+			if t.IsValid() {
+				b := t.As16()
+				av = b[:]
+			}
 		}
-		if av = av.To16(); av == nil {
+		if av == nil {
 			return nil, errInvalidFormat
 		}
 		return av, nil
 	case DTypeByte:
-		av, ok := v.(byte)
+		av, ok := toUint(v, math.MaxUint8)
 		if !ok {
 			return nil, errInvalidFormat
 		}
-		return []byte{av}, nil
+		return []byte{byte(av)}, nil
 	case DTypeEth:
-		av, ok := v.(net.HardwareAddr)
-		if !ok || len(av) != 6 {
+		var av []byte
+		switch t := v.(type) {
+		case net.HardwareAddr:
+			av = t
+		case []byte:
+			av = t
+		}
+		if len(av) != 6 {
 			return nil, errInvalidFormat
 		}
 		return av, nil
 	case DTypeShort:
-		av, ok := v.(uint16)
+		av, ok := toUint(v, math.MaxUint16)
 		if !ok {
 			return nil, errInvalidFormat
 		}
 		b := make([]byte, 2)
-		binary.BigEndian.PutUint16(b, av)
+		binary.BigEndian.PutUint16(b, uint16(av))
 		return b, nil
 	}
 	return nil, errInvalidFormat
 }
 
+// value of any integer type, false if it is negative or above max
+func toUint(v interface{}, max uint64) (u uint64, ok bool) {
+	switch av := v.(type) {
+	case uint:
+		u = uint64(av)
+	case uint8:
+		u = uint64(av)
+	case uint16:
+		u = uint64(av)
+	case uint32:
+		u = uint64(av)
+	case uint64:
+		u = av
+	default:
+		i, ok := toInt(v)
+		if !ok || i < 0 {
+			return 0, false
+		}
+		u = uint64(i)
+	}
+	return u, u <= max
+}
+
+// value of signed integer types and unsigned ones up to MaxInt64
+func toInt(v interface{}) (int64, bool) {
+	switch av := v.(type) {
+	case int:
+		return int64(av), true
+	case int8:
+		return int64(av), true
+	case int16:
+		return int64(av), true
+	case int32:
+		return int64(av), true
+	case int64:
+		return av, true
+	case uint8:
+		return int64(av), true
+	case uint16:
+		return int64(av), true
+	case uint32:
+		return int64(av), true
+	case uint:
+		if uint64(av) <= math.MaxInt64 {
+			return int64(av), true
+		}
+	case uint64:
+		if av <= math.MaxInt64 {
+			return int64(av), true
+		}
+	}
+	return 0, false
+}
+
+// Add attr, standard attrs are known without dictionary
 func (p *Packet) AddAttr(atype AttrType, vid VendorID, vtype VendorType, tag byte, data interface{}) error {
-	var err error
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	return p.addAttr(atype, vid, vtype, stdAttrFull(atype, vid, vtype), tag, data)
+}
 
+// Add attr by dictionary name, standard attrs are known without dictionary
+func (p *Packet) AddAttrByName(name string, data interface{}) error {
+	return p.AddAttrByNameTag(name, 0, data)
+}
+
+func (p *Packet) AddAttrByNameTag(name string, tag byte, data interface{}) error {
 	if p == nil {
 		return errors.New("Packet empty")
 	}
-	attr := &Attr{
-		atype: atype,
-		ad:    GetAttrByAttrFull(atype, vid, vtype),
+	ad := stdAttrByName(name)
+	if ad == nil {
+		return errors.New("Attribute not found: " + name)
+	}
+	return p.addAttr(ad.atype, ad.vid, ad.vtype, ad, tag, data)
+}
+
+func (p *Packet) addAttr(atype AttrType, vid VendorID, vtype VendorType, ad *AttrData, tag byte, data interface{}) error {
+	attr, err := buildAttr(atype, vid, vtype, ad, tag, data)
+	if err != nil {
+		return err
 	}
+	if ad := attr.attrData(); ad.GetMaxCount() != 0 && p.countAttrs(ad) >= ad.max {
+		return errAttrCount
+	}
+	attr.pkt = p
+	p.appendAttr(attr)
+	return nil
+}
+
+// new attr not bound to packet, value is converted by data type
+func buildAttr(atype AttrType, vid VendorID, vtype VendorType, ad *AttrData, tag byte, data interface{}) (*Attr, error) {
+	var err error
+
+	attr := newAttr(Attr{
+		atype: atype,
+		ad:    ad,
+	})
 	if attr.IsVSA() {
 		attr.vid = vid
 		attr.vtype = vtype
+		attr.vf = vendorFormat(vid)
+		if !attr.vf.typeOK(vtype) {
+			return nil, errVSAType
+		}
+	} else if attr.IsExtended() {
+		if vtype > 0xff {
+			return nil, errExtType
+		}
+		attr.vid = vid // EVS if not 0
+		attr.vtype = vtype
 	}
 	if attr.ad == nil {
 		// for unknown attrs only raw data can be set
 		av, ok := data.([]byte)
 		if !ok {
-			return errInvalidFormat
+			return nil, errInvalidFormat
 		}
 		attr.data = av
 	} else {
-		if attr.data, err = attrConv(attr.ad.dtype, data); err != nil {
-			return err
+		if attr.data, err = valueConv(attr.ad, data); err != nil {
+			return nil, err
+		}
+		if attr.data, err = tagValue(attr.ad, attr.data); err != nil {
+			return nil, err
 		}
 		if attr.ad.IsTagged() {
 			attr.tag = tag
 		}
+		attr.clear = encSupported(attr.ad.enc)
+		if !encLenOK(attr.ad.enc, len(attr.data)) {
+			return nil, errEncLen
+		}
 	}
-	if attr.IsVSA() {
-		attr.alen = byte(len(attr.data) + 8)
-		attr.vlen = byte(len(attr.data) + 2)
-	} else {
-		attr.alen = byte(len(attr.data) + 2)
+	if attr.tooLong() {
+		return nil, errAttrTooLong
 	}
-	attr.pkt = p
-	p.attrs = append(p.attrs, attr)
-	return nil
+	if ad := attr.attrData(); ad.GetSize() != 0 && attr.valueLen() != ad.size {
+		return nil, errAttrSize
+	}
+	attr.setLen()
+	return attr, nil
+}
+
+// add string VSA, known or not in dictionary
+func (p *Packet) addVSAString(vid VendorID, vtype VendorType, v string) error {
+	ad := stdVSA(vid, vtype)
+	if ad != nil && ad.dtype == DTypeString {
+		return p.addAttr(AttrVSA, vid, vtype, ad, 0, v)
+	}
+	return p.addAttr(AttrVSA, vid, vtype, ad, 0, []byte(v))
 }
 
+// Show values of secret attrs in String, off by default
+func (p *Packet) SetShowSecrets(on bool) {
+	if p == nil {
+		return
+	}
+	p.show = on
+}
+
+// Packet dump, values of secret attrs are masked unless SetShowSecrets is on
 func (p *Packet) String() (r string) {
 	if p == nil {
 		return
 	}
 	r += fmt.Sprintf("Code: %s, ID: %d, Len: %d, Auth: %02x\n", p.code, p.id, p.len, p.auth)
-	for _, attr := range p.attrs {
-		if attr.ad != nil {
-			r += fmt.Sprintf("  %s: ", attr.ad.name)
-		} else {
-			if attr.atype == AttrVSA {
-				r += fmt.Sprintf("  VSA-%d-%d: ", attr.vid, attr.vtype)
-			} else {
-				r += fmt.Sprintf("  Attr-%d: ", attr.atype)
-			}
+	for _, attr := range p.attrList() {
+		r += fmt.Sprintf("  %s: %s\n", attr.GetName(), attr.valueString(p.show))
+	}
+	return
+}
+
+// value for output with tag, value name for named integer values,
+// secret value is masked unless show
+func (a *Attr) valueString(show bool) (r string) {
+	if a.ad.IsTagged() {
+		r = fmt.Sprintf("[%d] ", a.tag)
+	}
+	if !show && a.attrData().IsSecret() {
+		return r + redactMask
+	}
+	if name := a.GetValueName(); name != "" {
+		return r + name
+	}
+	switch ed := a.GetEData().(type) {
+	case []byte:
+		return r + fmt.Sprintf("%02x", ed)
+	default:
+		return r + fmt.Sprintf("%v", ed)
+	}
+}
+
+// Natural reply code for request, ack selects Accept/ACK over Reject/NAK.
+// 0 if there is no single reply code (Status-Server, replies).
+func replyCode(code RadiusCode, ack bool) RadiusCode {
+	switch code {
+	case AccessRequest:
+		if ack {
+			return AccessAccept
 		}
-		if attr.ad.IsTagged() {
-			r += fmt.Sprintf("[%d] ", attr.tag)
+		return AccessReject
+	case AccountingRequest:
+		return AccountingResponse
+	case DisconnectRequest:
+		if ack {
+			return DisconnectACK
 		}
-		ed := attr.GetEData()
-		switch ed.(type) {
-		case []byte:
-			r += fmt.Sprintf("%02x", ed.([]byte))
-		default:
-			r += fmt.Sprintf("%v", ed)
+		return DisconnectNAK
+	case CoARequest:
+		if ack {
+			return CoAACK
 		}
-		r += fmt.Sprint("\n")
+		return CoANAK
 	}
-	return
+	return 0
 }
 
+// Reply to packet, Authenticator of reply is calculated from request
+// Authenticator and secret on serialize. Proxy-State attrs are copied
+// (RFC 2865 5.33), Accounting-Request gets Accounting-Response code,
+// for other requests code must be set or use ReplyACK/ReplyNAK.
 func (p *Packet) Reply() *Packet {
 	if p == nil {
 		return nil
 	}
-	return &Packet{
+	r := p.newReply()
+	if p.code == AccountingRequest {
+		r.code = AccountingResponse
+	}
+	p.copyAttrs(r, AttrProxyState) // not encrypted, can't fail
+	return r
+}
+
+// Reply with Access-Accept, Accounting-Response, CoA-ACK or Disconnect-ACK
+// code depending on request, see Reply
+func (p *Packet) ReplyACK() *Packet {
+	r := p.Reply()
+	if r != nil {
+		r.code = replyCode(p.code, true)
+	}
+	return r
+}
+
+// Reply with Access-Reject, Accounting-Response, CoA-NAK or Disconnect-NAK
+// code depending on request, see Reply
+func (p *Packet) ReplyNAK() *Packet {
+	r := p.Reply()
+	if r != nil {
+		r.code = replyCode(p.code, false)
+	}
+	return r
+}
+
+// empty reply sharing request state
+func (p *Packet) newReply() *Packet {
+	return &Packet{ // vendor IDs are filled as VSAs are added
 		id:     p.id,
 		auth:   p.auth,
-		vids:   p.vids,
 		secret: p.secret,
 		udata:  p.udata,
+		tdata:  p.tdata.copy(),
+		rand:   p.rand,
+		mauth:  p.mauth,
+		maxLen: p.maxLen,
 		reply:  true,
+		pack:   p.pack,
 	}
 }
 
@@ -453,13 +980,97 @@ func (p *Packet) BufCalc() (sum int) {
 	if p == nil {
 		return
 	}
-	sum = MinPLen
-	for _, a := range p.attrs {
-		sum += int(a.alen)
+	return roundup64(p.Len() + len(p.pad))
+}
+
+// wire len of all attrs, Message-Authenticator is not counted unless kept
+// in place in exact mode (see emitMsgAuth)
+func (p *Packet) attrsLen() (n int) {
+	var vp vsaPacker
+	inPlace := p.msgAuthInPlace()
+	for _, a := range p.attrList() {
+		if a.atype == AttrMessageAuthenticator && !inPlace {
+			continue
+		}
+		n += a.wireLen()
+		if p.pack && vp.add(a) {
+			n -= 6 // VSA header is shared
+		}
 	}
-	return roundup64(sum)
+	return
 }
 
+// Packet in wire format, nil if packet is empty or can't be encoded
 func (p *Packet) Serialize() []byte {
-	return nil
+	if p == nil {
+		return nil
+	}
+	b, err := p.AppendTo(make([]byte, 0, p.BufCalc()))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// Append packet in wire format to buf, no allocations if buf has enough
+// capacity (see BufCalc), so per-connection buffers can be reused
+func (p *Packet) AppendTo(buf []byte) ([]byte, error) {
+	if p == nil {
+		return buf, errors.New("Packet empty")
+	}
+	return p.serialize(buf)
+}
+
+// append packet in wire format to b
+func (p *Packet) serialize(b []byte) (_ []byte, err error) {
+	if randAuthCode(p.code) && len(p.auth) != authLen {
+		if _, err = p.GenAuth(); err != nil {
+			return
+		}
+	}
+	if err = p.encryptAttrs(); err != nil {
+		return
+	}
+	off := len(b)
+	b = append(b, byte(p.code), p.id, 0, 0)
+	if len(p.auth) == authLen {
+		b = append(b, p.auth...)
+	} else {
+		b = append(b, zeroAuth[:]...)
+	}
+	if p.emitMsgAuth() {
+		b = append(b, byte(AttrMessageAuthenticator), msgAuthLen)
+		b = append(b, zeroAuth[:]...)
+	}
+	var (
+		vp      vsaPacker
+		vsa     int // offset of last VSA header
+		inPlace = p.msgAuthInPlace()
+	)
+	for _, a := range p.attrList() {
+		if a.atype == AttrMessageAuthenticator && !inPlace { // emitted first
+			continue
+		}
+		if p.pack && vp.add(a) {
+			n := len(b)
+			b = a.appendVendor(b)
+			b[vsa+1] += byte(len(b) - n)
+			continue
+		}
+		vsa = len(b)
+		if b, err = a.appendWire(b); err != nil {
+			return
+		}
+	}
+	pl := len(b) - off
+	if pl > p.getMaxLen() {
+		err = errPktTooLong
+		return
+	}
+	binary.BigEndian.PutUint16(b[off+2:], uint16(pl))
+	p.len = uint16(pl)
+	if err = p.signAuth(b[off:]); err != nil {
+		return
+	}
+	return append(b, p.pad...), nil
 }
@@ -0,0 +1,182 @@
+package radius
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+// Packet construction option
+type PacketOpt func(p *Packet) error
+
+// Create new packet and apply options in order
+func NewPacket(code RadiusCode, secret []byte, opts ...PacketOpt) (*Packet, error) {
+	p := &Packet{
+		code:   code,
+		secret: secret,
+	}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func NewAccessRequest(secret []byte, opts ...PacketOpt) (*Packet, error) {
+	return NewPacket(AccessRequest, secret, opts...)
+}
+
+func NewAccountingRequest(secret []byte, opts ...PacketOpt) (*Packet, error) {
+	return NewPacket(AccountingRequest, secret, opts...)
+}
+
+// add standard attr, works without dictionary
+func (p *Packet) addStd(at AttrType, tag byte, data interface{}) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	return p.addAttr(at, 0, 0, stdAttr(at), tag, data)
+}
+
+func WithID(id byte) PacketOpt {
+	return func(p *Packet) error {
+		p.id = id
+		return nil
+	}
+}
+
+func WithRand(r io.Reader) PacketOpt {
+	return func(p *Packet) error {
+		p.rand = r
+		return nil
+	}
+}
+
+func WithUserData(udata interface{}) PacketOpt {
+	return func(p *Packet) error {
+		p.udata = udata
+		return nil
+	}
+}
+
+// Max packet len, see Packet.SetMaxLen
+func WithMaxLen(n int) PacketOpt {
+	return func(p *Packet) error {
+		p.SetMaxLen(n)
+		return nil
+	}
+}
+
+// Require Message-Authenticator for Access-* packets and emit it as first
+// attr on serialize, replies inherit this
+func WithMsgAuth() PacketOpt {
+	return func(p *Packet) error {
+		p.mauth = true
+		return nil
+	}
+}
+
+// Pack VSAs of the same vendor in a row into one attr on serialize,
+// replies inherit this
+func WithPackedVSA() PacketOpt {
+	return func(p *Packet) error {
+		p.pack = true
+		return nil
+	}
+}
+
+// Show values of secret attrs in String, see Packet.SetShowSecrets
+func WithShowSecrets() PacketOpt {
+	return func(p *Packet) error {
+		p.show = true
+		return nil
+	}
+}
+
+// Any attr, same args as Packet.AddAttr
+func WithAttr(atype AttrType, vid VendorID, vtype VendorType, tag byte, data interface{}) PacketOpt {
+	return func(p *Packet) error {
+		return p.AddAttr(atype, vid, vtype, tag, data)
+	}
+}
+
+func WithVSA(vid VendorID, vtype VendorType, data interface{}) PacketOpt {
+	return WithAttr(AttrVSA, vid, vtype, 0, data)
+}
+
+func WithUserName(name string) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrUserName, 0, name)
+	}
+}
+
+// Password is kept in plain text in packet and encrypted on serialize
+func WithUserPassword(password string) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrUserPassword, 0, password)
+	}
+}
+
+func WithNASIP(ip net.IP) PacketOpt {
+	return func(p *Packet) error {
+		if ip.To4() == nil {
+			return p.addStd(AttrNASIPv6Address, 0, ip)
+		}
+		return p.addStd(AttrNASIPAddress, 0, ip)
+	}
+}
+
+func WithNASIdentifier(id string) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrNASIdentifier, 0, id)
+	}
+}
+
+func WithNASPort(port uint32) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrNASPort, 0, port)
+	}
+}
+
+func WithNASPortType(pt uint32) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrNASPortType, 0, pt)
+	}
+}
+
+func WithServiceType(st uint32) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrServiceType, 0, st)
+	}
+}
+
+func WithCallingStationID(id string) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrCallingStationID, 0, id)
+	}
+}
+
+func WithCalledStationID(id string) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrCalledStationID, 0, id)
+	}
+}
+
+func WithFramedIP(ip net.IP) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrFramedIPAddress, 0, ip)
+	}
+}
+
+func WithAcctStatusType(st uint32) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrAcctStatusType, 0, st)
+	}
+}
+
+func WithAcctSessionID(id string) PacketOpt {
+	return func(p *Packet) error {
+		return p.addStd(AttrAcctSessionID, 0, id)
+	}
+}
@@ -0,0 +1,116 @@
+package radius
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+var fuzzSecret = []byte("testing123")
+
+// attrs count, Message-Authenticator is always serialized once
+func countAttrs(p *Packet) (n int) {
+	for _, a := range p.attrList() {
+		if a.atype != AttrMessageAuthenticator {
+			n++
+		}
+	}
+	return
+}
+
+// parsed packet must re-serialize to bytes that parse to the same attrs
+func checkReserialize(t *testing.T, p *Packet) {
+	t.Helper()
+	p.SetSecret(fuzzSecret)
+	n := countAttrs(p)
+	b, err := p.AppendTo(nil)
+	if err == errPktTooLong {
+		return // e.g. packed VSAs split on parse, it is not a parse result
+	}
+	if err != nil {
+		t.Fatalf("re-serialize: %v", err)
+	}
+	if len(b) < MinPLen || int(binary.BigEndian.Uint16(b[2:])) > len(b) {
+		t.Fatalf("invalid header in %x", b)
+	}
+	q, err := ParsePacketOpts(b, &ParseOptions{MaxLen: MaxLongLen})
+	if err != nil {
+		t.Fatalf("re-parse of %x: %v", b, err)
+	}
+	if m := countAttrs(q); m != n {
+		t.Fatalf("attrs count %d after re-parse, want %d", m, n)
+	}
+}
+
+func FuzzParsePacket(f *testing.F) {
+	f.Add([]byte{1, 1, 0, 20, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, opts := range []*ParseOptions{
+			nil,
+			{Permissive: true},
+			{Exact: true},
+			{Lazy: true},
+		} {
+			p, err := ParsePacketOpts(b, opts)
+			if err != nil {
+				continue
+			}
+			_ = p.String()
+			checkReserialize(t, p)
+		}
+	})
+}
+
+func FuzzParseVSA(f *testing.F) {
+	f.Add([]byte{0, 0, 0x01, 0x37, 1, 6, 'a', 'b', 'c', 'd'})
+	f.Fuzz(func(t *testing.T, vsa []byte) {
+		p := AcquirePacket()
+		defer ReleasePacket(p)
+		if _, _, err := p.parseVSA(vsa, nil); err != nil {
+			return
+		}
+		_ = p.String()
+		if len(vsa) > 253 {
+			return
+		}
+		b := make([]byte, MinPLen, MinPLen+2+len(vsa))
+		b[0] = byte(AccountingRequest)
+		b = append(b, byte(AttrVSA), byte(len(vsa)+2))
+		b = append(b, vsa...)
+		binary.BigEndian.PutUint16(b[2:], uint16(len(b)))
+		q, err := ParsePacket(b)
+		if err != nil {
+			t.Fatalf("parse of valid VSA %x: %v", vsa, err)
+		}
+		checkReserialize(t, q)
+	})
+}
+
+// exact mode keeps Message-Authenticator where it is in valid packet
+func TestExactMsgAuthInPlace(t *testing.T) {
+	b := make([]byte, MinPLen, 64)
+	b[0], b[1] = byte(AccessRequest), 7
+	for i := 4; i < MinPLen; i++ {
+		b[i] = byte(i)
+	}
+	b = append(b, byte(AttrUserName), 5, 'b', 'o', 'b')
+	off := len(b)
+	b = append(b, byte(AttrMessageAuthenticator), msgAuthLen)
+	b = append(b, make([]byte, authLen)...)
+	binary.BigEndian.PutUint16(b[2:], uint16(len(b)))
+	copy(b[off+2:], calcMsgAuth(b, b[4:MinPLen], fuzzSecret, off+2))
+	if err := VerifyRequestRawStrict(b, fuzzSecret); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParsePacketOpts(b, &ParseOptions{Exact: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetSecret(fuzzSecret)
+	if p.Len() != len(b) {
+		t.Fatalf("Len = %d, want %d", p.Len(), len(b))
+	}
+	if s := p.Serialize(); !bytes.Equal(s, b) {
+		t.Fatalf("Serialize = %x, want %x", s, b)
+	}
+}
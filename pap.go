@@ -0,0 +1,119 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var (
+	errNoPAP    = errors.New("User-Password missing")
+	errBadPAP   = errors.New("User-Password mismatch")
+	errPwScheme = errors.New("Password scheme not supported")
+	errPwStored = errors.New("Invalid stored password")
+	errPwDES    = errors.New("DES crypt password scheme not supported")
+)
+
+// RFC 2307 schemes, salt follows digest in salted ones
+var pwSchemes = map[string]struct {
+	h      crypto.Hash
+	salted bool
+}{
+	"MD5":     {crypto.MD5, false},
+	"SMD5":    {crypto.MD5, true},
+	"SHA":     {crypto.SHA1, false},
+	"SSHA":    {crypto.SHA1, true},
+	"SHA256":  {crypto.SHA256, false},
+	"SSHA256": {crypto.SHA256, true},
+	"SHA512":  {crypto.SHA512, false},
+	"SSHA512": {crypto.SHA512, true},
+}
+
+// check password against {SCHEME} digest, base64 or hex (unsalted only)
+func schemeVerify(scheme, password, v string) error {
+	s, ok := pwSchemes[scheme]
+	if !ok {
+		return errPwScheme
+	}
+	h := newHash(s.h)
+	d, err := hex.DecodeString(v)
+	if err != nil || len(d) != h.Size() || s.salted {
+		if d, err = base64.StdEncoding.DecodeString(v); err != nil {
+			return errPwStored
+		}
+	}
+	if len(d) < h.Size() || (!s.salted && len(d) != h.Size()) {
+		return errPwStored
+	}
+	h.Write([]byte(password))
+	h.Write(d[h.Size():])
+	if subtle.ConstantTimeCompare(h.Sum(nil), d[:h.Size()]) != 1 {
+		return errBadPAP
+	}
+	return nil
+}
+
+// Check clear text password against stored one. Stored password may be
+// crypt(3) hash ($1$, $5$, $6$), bcrypt hash ($2a$, $2b$, $2y$), RFC 2307
+// {MD5}, {SMD5}, {SHA}, {SSHA}, {SHA256}, {SSHA256}, {SHA512}, {SSHA512}
+// digest, {CRYPT} followed by crypt(3) or bcrypt hash, {CLEARTEXT} followed
+// by password, without prefix it is clear text. Clear text password which
+// starts with '$' or '{' is taken for hash, store it as {CLEARTEXT}.
+func VerifyPassword(password, stored string) error {
+	if strings.HasPrefix(stored, "{") {
+		scheme, v, ok := strings.Cut(stored[1:], "}")
+		if !ok {
+			return errPwStored
+		}
+		switch scheme = strings.ToUpper(scheme); scheme {
+		case "CRYPT":
+			return hashVerify(password, v)
+		case "CLEAR", "CLEARTEXT":
+			return clearVerify(password, v)
+		}
+		return schemeVerify(scheme, password, v)
+	}
+	if strings.HasPrefix(stored, "$") {
+		return hashVerify(password, stored)
+	}
+	return clearVerify(password, stored)
+}
+
+// check password against crypt(3) or bcrypt hash, DES based crypt (no
+// $id$ prefix) is not supported
+func hashVerify(password, stored string) error {
+	switch {
+	case strings.HasPrefix(stored, "$2"):
+		return bcryptVerify(password, stored)
+	case strings.HasPrefix(stored, "$"):
+		return cryptVerify(password, stored)
+	}
+	return errPwDES
+}
+
+func clearVerify(password, stored string) error {
+	if subtle.ConstantTimeCompare([]byte(password), []byte(stored)) != 1 {
+		return errBadPAP
+	}
+	return nil
+}
+
+// Verify User-Password of request against stored password, see
+// VerifyPassword for supported formats
+func (p *Packet) VerifyPAP(stored string) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	a := p.GetAttr(AttrUserPassword)
+	if a == nil {
+		return errNoPAP
+	}
+	pw, err := a.Decrypt()
+	if err != nil {
+		return err
+	}
+	return VerifyPassword(string(pw), stored)
+}
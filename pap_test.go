@@ -0,0 +1,57 @@
+package radius
+
+import (
+	"testing"
+)
+
+func TestVerifyPassword(t *testing.T) {
+	const long = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789chars after 72 are ignored"
+	tests := []struct {
+		pw     string
+		stored string
+		want   error
+	}{
+		// crypt(3) MD5
+		{"password", "$1$saltstri$qQY4WxjABChYG1ccLpfkz/", nil},
+		{"passwore", "$1$saltstri$qQY4WxjABChYG1ccLpfkz/", errBadPAP},
+		// crypt(3) SHA, examples of SHA-crypt spec
+		{"Hello world!", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", nil},
+		{"Hello world!", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", nil},
+		{"Hello world!", "$5$rounds=5000$toolongsaltstrin$0vuwUia3Nx9V/DqToMS8YLcfXpEXmSaC8wgguLIbus2", nil},
+		{"Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", nil},
+		{"Hello world!", "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.", nil},
+		{"Hello world?", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", errBadPAP},
+		{"x", "$6$rounds=x$salt$", errCrypt},
+		{"x", "$7$salt$hash", errPwScheme},
+		// bcrypt, OpenBSD test vectors
+		{"U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", nil},
+		{"U*U*", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.VGOzA784oUp/Z0DY336zx7pLYAy0lwK", nil},
+		{"U*U*U", "$2a$05$XXXXXXXXXXXXXXXXXXXXXOAcXxm9kjPGEMsLznoKqmqw7tc8WCx4a", nil},
+		{"", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.7uG0VCzI2bS7j6ymqJi9CdcdxiRTWNy", nil},
+		{long, "$2a$05$abcdefghijklmnopqrstuu5s2v8.iXieOjg/.AySBTTZIIVFJeBui", nil},
+		{"U*U", "$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", nil},
+		{"U*U*", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", errBadPAP},
+		// RFC 2307
+		{"password", "{MD5}X03MO1qnZdYdgyfeuILPmQ==", nil},
+		{"password", "{md5}5f4dcc3b5aa765d61d8327deb882cf99", nil},
+		{"passwore", "{MD5}X03MO1qnZdYdgyfeuILPmQ==", errBadPAP},
+		{"secret", "{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", nil},
+		{"secreT", "{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", errBadPAP},
+		{"secret", "{SSHA}uJDd0BIdJ9Z7", errPwStored},
+		{"secret", "{XYZ}abc", errPwScheme},
+		{"password", "{crypt}$1$saltstri$qQY4WxjABChYG1ccLpfkz/", nil},
+		{"U*U", "{CRYPT}$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", nil},
+		{"password", "{crypt}abJnggxhB/yWI", errPwDES},
+		// clear text
+		{"password", "password", nil},
+		{"password", "Password", errBadPAP},
+		{"$secret", "{CLEARTEXT}$secret", nil},
+		{"{x}y", "{CLEAR}{x}y", nil},
+		{"x", "{x", errPwStored},
+	}
+	for _, tt := range tests {
+		if err := VerifyPassword(tt.pw, tt.stored); err != tt.want {
+			t.Errorf("VerifyPassword(%q, %q) = %v, want %v", tt.pw, tt.stored, err, tt.want)
+		}
+	}
+}
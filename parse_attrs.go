@@ -0,0 +1,120 @@
+package radius
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Attr visitor, return false to stop walking
+type AttrFunc func(t AttrType, vid VendorID, vtype VendorType, tag byte, data []byte) bool
+
+var errVSAShort = errors.New("VSA too short")
+
+// Walk packet attributes in place without allocations.
+// data points into buf; tags are stripped for tagged attrs known to dictionary
+// or builtin standard attrs. Extended attrs come with extended type in vtype,
+// EVS with vendor ID and type in vid and vtype.
+func ParseAttrs(buf []byte, fn AttrFunc) error {
+	pl, err := checkHeader(buf, MaxLongLen)
+	if err != nil {
+		return err
+	}
+	var (
+		evid VendorID   // vendor of long EVS to be continued
+		evt  VendorType // type of long EVS to be continued
+		cont bool       // previous long extended attr has More flag
+	)
+	rb := rBuf{buf: buf[MinPLen:pl], bl: pl - MinPLen}
+	for rb.getLeft() > 0 {
+		at, ad, err := rb.getAttr()
+		if err != nil {
+			return err
+		}
+		if isExtended(AttrType(at)) { // parts of long attr come one by one
+			if err = checkExt(AttrType(at), ad); err != nil {
+				return err
+			}
+			ext, more, data := splitExt(AttrType(at), ad)
+			var ead *AttrData
+			switch {
+			case ext != ExtEVS:
+				evid, ead = 0, stdExtAttr(AttrType(at), ext)
+			case cont && evid != 0: // EVS header is in first part only
+				ext, ead = evt, GetEVSByAttr(AttrType(at), evid, evt)
+			default:
+				if evid, evt, data, err = splitEVS(data); err != nil {
+					return err
+				}
+				ext, ead = evt, GetEVSByAttr(AttrType(at), evid, evt)
+			}
+			cont = more
+			tag, data := splitTag(ead, data)
+			if !fn(AttrType(at), evid, ext, tag, data) {
+				return nil
+			}
+			continue
+		}
+		cont = false
+		if AttrType(at) != AttrVSA {
+			tag, data := splitTag(stdAttr(AttrType(at)), ad)
+			if !fn(AttrType(at), 0, 0, tag, data) {
+				return nil
+			}
+			continue
+		}
+		if len(ad) < 6 {
+			return errVSAShort
+		}
+		vid := VendorID(binary.BigEndian.Uint32(ad))
+		vf := vendorFormat(vid)
+		for rest := ad[4:]; len(rest) > 0; {
+			var (
+				vt VendorType
+				vd []byte
+			)
+			if vt, vd, _, rest, err = vf.next(rest); err != nil {
+				return err
+			}
+			tag, data := splitTag(stdVSA(vid, vt), vd)
+			if !fn(AttrVSA, vid, vt, tag, data) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Split tag from tagged attr value (RFC 2868 3.1). Integer value carries
+// tag in high byte, so 3 bytes are left. String value has no tag if first
+// byte is above 0x1F, except Tunnel-Password where tag is always present.
+func splitTag(ad *AttrData, v []byte) (byte, []byte) {
+	if !ad.IsTagged() || len(v) == 0 {
+		return 0, v
+	}
+	if dtypeLen(ad.dtype) == 0 && ad.enc != AttrEncTun && v[0] > 0x1f {
+		return 0, v
+	}
+	return v[0], v[1:]
+}
+
+// Value without high byte for tagged integers, tag takes its place on wire
+func tagValue(ad *AttrData, v []byte) ([]byte, error) {
+	if !ad.IsTagged() || dtypeLen(ad.dtype) != 4 {
+		return v, nil
+	}
+	if len(v) != 4 || v[0] != 0 {
+		return nil, errInvalidFormat // value must fit in 24 bits
+	}
+	return v[1:], nil
+}
+
+// uint32 value, tagged integers are 3 bytes without tag
+func intData(ad *AttrData, v []byte) (uint32, bool) {
+	switch {
+	case len(v) == 4:
+		return binary.BigEndian.Uint32(v), true
+	case len(v) == 3 && ad.IsTagged():
+		return uint32(v[0])<<16 | uint32(v[1])<<8 | uint32(v[2]), true
+	}
+	return 0, false
+}
@@ -0,0 +1,50 @@
+package radius
+
+import (
+	"sync"
+)
+
+var (
+	pktPool = sync.Pool{
+		New: func() interface{} { return new(Packet) },
+	}
+	attrPool = sync.Pool{
+		New: func() interface{} { return new(Attr) },
+	}
+)
+
+// Attr from pool initialized with a
+func newAttr(a Attr) *Attr {
+	attr := attrPool.Get().(*Attr)
+	*attr = a
+	return attr
+}
+
+// Empty packet from pool, parsed packets are taken from pool too.
+// Return it with ReleasePacket when done to avoid allocations.
+func AcquirePacket() *Packet {
+	return pktPool.Get().(*Packet)
+}
+
+// Return packet and its attrs to pool. Neither packet nor its attrs
+// (including ones got from Get* methods) may be used after release.
+func ReleasePacket(p *Packet) {
+	if p == nil {
+		return
+	}
+	for i, a := range p.attrs {
+		*a = Attr{}
+		attrPool.Put(a)
+		p.attrs[i] = nil
+	}
+	attrs := p.attrs[:0]
+	vset := p.vset
+	for v := range vset {
+		delete(vset, v)
+	}
+	*p = Packet{
+		attrs: attrs,
+		vset:  vset, // vids slice may be held by GetVIDs caller, so not reused
+	}
+	pktPool.Put(p)
+}
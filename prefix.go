@@ -0,0 +1,50 @@
+package radius
+
+import (
+	"net"
+)
+
+// IPv4-Prefix and IPv6-Prefix value (RFC 8044 3.10, 3.11): reserved byte,
+// prefix len and prefix. IPv6 prefix has only significant bytes, IPv4 has 4.
+func encodePrefix(n *net.IPNet, v6 bool) ([]byte, error) {
+	if n == nil {
+		return nil, errInvalidFormat
+	}
+	ones, bits := n.Mask.Size()
+	ip := n.IP.Mask(n.Mask) // bits beyond prefix must be zero
+	if v6 {
+		ip = ip.To16()
+		if ip == nil || bits != 128 {
+			return nil, errInvalidFormat
+		}
+		b := []byte{0, byte(ones)}
+		return append(b, ip[:(ones+7)/8]...), nil
+	}
+	ip = ip.To4()
+	if ip == nil || bits != 32 {
+		return nil, errInvalidFormat
+	}
+	b := []byte{0, byte(ones)}
+	return append(b, ip...), nil
+}
+
+func decodePrefix(data []byte, v6 bool) (*net.IPNet, bool) {
+	if len(data) < 2 || data[0] != 0 {
+		return nil, false
+	}
+	ones := int(data[1])
+	if v6 {
+		if len(data) > 18 || ones > 128 || (len(data)-2)*8 < ones {
+			return nil, false
+		}
+		ip := make(net.IP, net.IPv6len)
+		copy(ip, data[2:])
+		mask := net.CIDRMask(ones, 128)
+		return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, true
+	}
+	if len(data) != 6 || ones > 32 {
+		return nil, false
+	}
+	mask := net.CIDRMask(ones, 32)
+	return &net.IPNet{IP: net.IP(data[2:]).Mask(mask), Mask: mask}, true
+}
@@ -0,0 +1,70 @@
+package radius
+
+// Attribute added by profile
+type ProfileAttr struct {
+	Codes     []RadiusCode // Packet codes to add attr to, empty - all
+	Type      AttrType
+	VID       VendorID
+	VType     VendorType
+	Tag       byte
+	Value     interface{} // Same as Packet.AddAttr data
+	IfMissing bool        // Add only if packet has no such attr
+}
+
+// Default attributes for a peer, e.g. NAS-Identifier for every request
+// or operator VSA for every Access-Accept
+type Profile struct {
+	Attrs []ProfileAttr
+}
+
+func (pa *ProfileAttr) match(code RadiusCode) bool {
+	if len(pa.Codes) == 0 {
+		return true
+	}
+	for _, c := range pa.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Packet) hasAttrFull(at AttrType, vid VendorID, vtype VendorType) bool {
+	if at != AttrVSA {
+		return p.HasAttr(at)
+	}
+	_, ok := p.getVSAData(vid, vtype)
+	return ok
+}
+
+// Add profile attrs matching packet code
+func (pr *Profile) Apply(p *Packet) error {
+	if pr == nil {
+		return nil
+	}
+	code := p.GetCode()
+	for i := range pr.Attrs {
+		pa := &pr.Attrs[i]
+		if !pa.match(code) {
+			continue
+		}
+		if pa.IfMissing && p.hasAttrFull(pa.Type, pa.VID, pa.VType) {
+			continue
+		}
+		var err error
+		if pa.Type == AttrVSA {
+			err = p.AddAttr(pa.Type, pa.VID, pa.VType, pa.Tag, pa.Value)
+		} else {
+			err = p.addStd(pa.Type, pa.Tag, pa.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Profile as construction option
+func WithProfile(pr *Profile) PacketOpt {
+	return pr.Apply
+}
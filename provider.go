@@ -0,0 +1,80 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/md5"
+	"crypto/rand"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"hash"
+	"io"
+	"sync/atomic"
+)
+
+// Crypto primitives used by package: authenticators, Message-Authenticator,
+// attr encryption, CHAP, MS-CHAP, Digest and password hashes. Default one is
+// standard library, replace it with SetCryptoProvider to use FIPS wrappers
+// or hardware backed implementations. MD4 and DES of MS-CHAP and Blowfish
+// of bcrypt don't use provider.
+type CryptoProvider interface {
+	// New hash, package uses MD5, SHA1, SHA256 and SHA512
+	Hash(h crypto.Hash) hash.Hash
+	// New HMAC with key, package uses HMAC-MD5 and HMAC-SHA256
+	HMAC(h crypto.Hash, key []byte) hash.Hash
+	// Default random source of packets, see Packet.SetRand
+	Rand() io.Reader
+}
+
+type stdProvider struct{}
+
+func (stdProvider) Hash(h crypto.Hash) hash.Hash {
+	return h.New()
+}
+
+func (stdProvider) HMAC(h crypto.Hash, key []byte) hash.Hash {
+	return hmac.New(h.New, key)
+}
+
+func (stdProvider) Rand() io.Reader {
+	return rand.Reader
+}
+
+type cryptoBox struct {
+	CryptoProvider
+}
+
+var cryptoProv atomic.Value // cryptoBox
+
+func init() {
+	cryptoProv.Store(cryptoBox{stdProvider{}})
+}
+
+// Set crypto provider of package, nil restores standard library one.
+// Set it on startup, packets in flight may use either provider.
+func SetCryptoProvider(cp CryptoProvider) {
+	if cp == nil {
+		cp = stdProvider{}
+	}
+	cryptoProv.Store(cryptoBox{cp})
+}
+
+func GetCryptoProvider() CryptoProvider {
+	return cryptoProv.Load().(cryptoBox).CryptoProvider
+}
+
+func newHash(h crypto.Hash) hash.Hash {
+	return GetCryptoProvider().Hash(h)
+}
+
+func newHMAC(h crypto.Hash, key []byte) hash.Hash {
+	return GetCryptoProvider().HMAC(h, key)
+}
+
+// sum of b with hash h
+func hashSum(h crypto.Hash, b []byte) []byte {
+	d := newHash(h)
+	d.Write(b)
+	return d.Sum(nil)
+}
@@ -0,0 +1,59 @@
+package radius
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	ErrProxyLoop  = errors.New("Proxy loop detected")
+	ErrProxyDepth = errors.New("Too many Proxy-State attributes")
+)
+
+// Number of Proxy-State attrs in packet
+func (p *Packet) ProxyStateCount() (n int) {
+	if !p.HasAttr(AttrProxyState) {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.atype == AttrProxyState {
+			n++
+		}
+	}
+	return
+}
+
+// Check packet for Proxy-State with our own value
+func (p *Packet) HasProxyState(state []byte) bool {
+	if !p.HasAttr(AttrProxyState) {
+		return false
+	}
+	for _, a := range p.attrList() {
+		if a.atype == AttrProxyState && bytes.Equal(a.data, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check Proxy-State depth (maxDepth <= 0 - no limit) and loop with our state
+func (p *Packet) CheckProxyState(state []byte, maxDepth int) error {
+	if len(state) != 0 && p.HasProxyState(state) {
+		return ErrProxyLoop
+	}
+	if maxDepth > 0 && p.ProxyStateCount() >= maxDepth {
+		return ErrProxyDepth
+	}
+	return nil
+}
+
+// Add our Proxy-State before forwarding, fails on loop or depth limit
+func (p *Packet) AddProxyState(state []byte, maxDepth int) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if err := p.CheckProxyState(state, maxDepth); err != nil {
+		return err
+	}
+	return p.addStd(AttrProxyState, 0, state)
+}
@@ -0,0 +1,231 @@
+package radius
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// crypt(3) MD5 ($1$) and SHA ($5$, $6$) password hashes
+
+const cryptB64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const (
+	md5CryptSaltLen = 8
+	shaCryptSaltLen = 16
+	shaCryptRounds  = 5000
+	shaCryptMinRnd  = 1000
+	shaCryptMaxRnd  = 999999999
+)
+
+var errCrypt = errors.New("Invalid crypt hash")
+
+// byte order of encoded digest, 3 bytes per 4 chars, tail is shorter
+var (
+	md5CryptOrder = []int{
+		0, 6, 12, 1, 7, 13, 2, 8, 14, 3, 9, 15, 4, 10, 5, 11,
+	}
+	sha256CryptOrder = []int{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14,
+		15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29, 31, 30,
+	}
+	sha512CryptOrder = []int{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4,
+		47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+		31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35,
+		15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19,
+		62, 20, 41, 63,
+	}
+)
+
+// crypt base64, little endian 24 bit groups of sum bytes in order
+func cryptEncode(sum []byte, order []int) string {
+	var sb strings.Builder
+	for i := 0; i < len(order); i += 3 {
+		var v uint32
+		n := len(order) - i
+		if n > 3 {
+			n = 3
+		}
+		for _, k := range order[i : i+n] {
+			v = v<<8 | uint32(sum[k])
+		}
+		for c := 0; c <= n; c++ {
+			sb.WriteByte(cryptB64[v&0x3f])
+			v >>= 6
+		}
+	}
+	return sb.String()
+}
+
+// repeat b to n bytes
+func cryptFill(h hash.Hash, b []byte, n int) {
+	for ; n > len(b); n -= len(b) {
+		h.Write(b)
+	}
+	h.Write(b[:n])
+}
+
+// MD5-crypt (FreeBSD), salt is up to 8 chars
+func md5Crypt(pw, salt []byte) string {
+	if len(salt) > md5CryptSaltLen {
+		salt = salt[:md5CryptSaltLen]
+	}
+	h := newHash(crypto.MD5)
+	h.Write(pw)
+	h.Write(salt)
+	h.Write(pw)
+	alt := h.Sum(nil)
+	h.Reset()
+	h.Write(pw)
+	h.Write([]byte("$1$"))
+	h.Write(salt)
+	if len(pw) > 0 {
+		cryptFill(h, alt, len(pw))
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	sum := h.Sum(nil)
+	for i := 0; i < 1000; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(pw)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write(salt)
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 != 0 {
+			h.Write(sum)
+		} else {
+			h.Write(pw)
+		}
+		sum = h.Sum(sum[:0])
+	}
+	return "$1$" + string(salt) + "$" + cryptEncode(sum, md5CryptOrder)
+}
+
+// SHA-crypt (Drepper), id is 5 or 6, rounds 0 if not set in hash
+func shaCrypt(id byte, pw, salt []byte, rounds int) string {
+	hid, order := crypto.SHA256, sha256CryptOrder
+	if id == '6' {
+		hid, order = crypto.SHA512, sha512CryptOrder
+	}
+	prefix := "$" + string(id) + "$"
+	if rounds != 0 {
+		rounds = min(max(rounds, shaCryptMinRnd), shaCryptMaxRnd)
+		prefix += "rounds=" + strconv.Itoa(rounds) + "$"
+	} else {
+		rounds = shaCryptRounds
+	}
+	if len(salt) > shaCryptSaltLen {
+		salt = salt[:shaCryptSaltLen]
+	}
+	h := newHash(hid)
+	h.Write(pw)
+	h.Write(salt)
+	h.Write(pw)
+	alt := h.Sum(nil)
+	h.Reset()
+	h.Write(pw)
+	h.Write(salt)
+	if len(pw) > 0 {
+		cryptFill(h, alt, len(pw))
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write(alt)
+		} else {
+			h.Write(pw)
+		}
+	}
+	sum := h.Sum(nil)
+	h.Reset()
+	for range pw {
+		h.Write(pw)
+	}
+	dp := h.Sum(nil)
+	p := make([]byte, 0, len(pw))
+	for len(p) < len(pw) {
+		p = append(p, dp[:min(len(dp), len(pw)-len(p))]...)
+	}
+	h.Reset()
+	for i := 0; i < 16+int(sum[0]); i++ {
+		h.Write(salt)
+	}
+	s := h.Sum(nil)[:len(salt)]
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(sum)
+		} else {
+			h.Write(p)
+		}
+		sum = h.Sum(sum[:0])
+	}
+	return prefix + string(salt) + "$" + cryptEncode(sum, order)
+}
+
+// check password against $1$, $5$ or $6$ hash
+func cryptVerify(password, stored string) error {
+	if len(stored) < 3 || stored[0] != '$' || stored[2] != '$' {
+		return errCrypt
+	}
+	id, rest := stored[1], stored[3:]
+	var want string
+	switch id {
+	case '1':
+		salt, _, ok := strings.Cut(rest, "$")
+		if !ok {
+			return errCrypt
+		}
+		want = md5Crypt([]byte(password), []byte(salt))
+	case '5', '6':
+		rounds := 0
+		if r, ok := strings.CutPrefix(rest, "rounds="); ok {
+			n, tail, ok := strings.Cut(r, "$")
+			if !ok {
+				return errCrypt
+			}
+			var err error
+			if rounds, err = strconv.Atoi(n); err != nil || rounds <= 0 {
+				return errCrypt
+			}
+			rest = tail
+		}
+		salt, _, ok := strings.Cut(rest, "$")
+		if !ok {
+			return errCrypt
+		}
+		want = shaCrypt(id, []byte(password), []byte(salt), rounds)
+	default:
+		return errPwScheme
+	}
+	if subtle.ConstantTimeCompare([]byte(want), []byte(stored)) != 1 {
+		return errBadPAP
+	}
+	return nil
+}
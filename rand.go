@@ -0,0 +1,29 @@
+package radius
+
+import (
+	"io"
+)
+
+// Source for authenticators, salts and other generated values.
+// Set it to a fixed stream to get reproducible packets (golden tests).
+// Replies inherit it, nil restores default one of crypto provider, use
+// SetCryptoProvider to replace it for all packets.
+func (p *Packet) SetRand(r io.Reader) {
+	if p == nil {
+		return
+	}
+	p.rand = r
+}
+
+func (p *Packet) GetRand() io.Reader {
+	if p == nil || p.rand == nil {
+		return GetCryptoProvider().Rand()
+	}
+	return p.rand
+}
+
+// fill b from packet random source
+func (p *Packet) randRead(b []byte) error {
+	_, err := io.ReadFull(p.GetRand(), b)
+	return err
+}
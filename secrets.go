@@ -0,0 +1,92 @@
+package radius
+
+import (
+	"errors"
+)
+
+// Candidate secrets for shared secret rotation: request is verified against
+// each one, matching secret is set as packet secret so replies use it.
+
+var (
+	errNoSecretMatch = errors.New("No secret matches")
+	errSecretUnknown = errors.New("Secret can't be chosen without Message-Authenticator")
+)
+
+// Set candidate secrets tried by MatchSecret in order, e.g. new and old
+// secret during rotation
+func (p *Packet) SetSecrets(secrets ...[]byte) {
+	if p == nil {
+		return
+	}
+	p.secs = secrets
+}
+
+func (p *Packet) GetSecrets() [][]byte {
+	if p == nil {
+		return nil
+	}
+	return p.secs
+}
+
+// Verify parsed request against candidate secrets (packet secret if none
+// set), matching secret is set as packet secret, so encrypted attrs are
+// decrypted and replies are signed with it. See MatchSecretRaw.
+func (p *Packet) MatchSecret() ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("Packet empty")
+	}
+	if p.data == nil {
+		return nil, errors.New("Packet not parsed")
+	}
+	secrets := p.secs
+	if len(secrets) == 0 {
+		secrets = [][]byte{p.secret}
+	}
+	secret, err := matchSecret(p.data, secrets, p.mauth)
+	if err != nil {
+		return nil, err
+	}
+	p.SetSecret(secret)
+	return secret, nil
+}
+
+// Verify raw request against secrets in order and return first matching
+// one, see VerifyRequestRaw. Access-Request without Message-Authenticator
+// doesn't depend on secret, it is accepted only with single candidate.
+func MatchSecretRaw(buf []byte, secrets [][]byte) ([]byte, error) {
+	return matchSecret(buf, secrets, false)
+}
+
+func matchSecret(buf []byte, secrets [][]byte, strict bool) ([]byte, error) {
+	switch len(secrets) {
+	case 0:
+		return nil, errNoSecret
+	case 1:
+		if err := verifyRequest(buf, secrets[0], strict); err != nil {
+			return nil, err
+		}
+		return secrets[0], nil
+	}
+	pl, err := checkHeader(buf, MaxLongLen)
+	if err != nil {
+		return nil, err
+	}
+	if randAuthCode(RadiusCode(buf[0])) {
+		off, err := findMsgAuth(buf[:pl])
+		if err != nil {
+			return nil, err
+		}
+		if off == 0 {
+			return nil, errSecretUnknown
+		}
+	}
+	for _, s := range secrets {
+		if err = verifyRequest(buf, s, strict); err == nil {
+			return s, nil
+		}
+		if err != errBadAuth && err != errBadMsgAuth {
+			return nil, err
+		}
+	}
+	return nil, errNoSecretMatch
+}
@@ -0,0 +1,77 @@
+package radius
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+var errSockOptUnsupported = errors.New("Socket option not supported on this platform")
+
+// Socket options for RADIUS sockets, applied via Control of
+// net.Dialer or net.ListenConfig. Use separate SockOpts (and dialer)
+// per destination for per-destination settings.
+type SockOpts struct {
+	TOS    int    // IPv4 TOS / IPv6 traffic class, <= 0 - not set unless TOSSet
+	TOSSet bool   // set TOS even if 0, e.g. to clear inherited marking
+	Device string // interface or VRF device to bind (Linux only), empty - not set
+}
+
+// Set TOS explicitly, 0 is applied too
+func (so *SockOpts) SetTOS(tos byte) {
+	so.TOS = int(tos)
+	so.TOSSet = true
+}
+
+// Set TOS from DSCP value (6 bit), DSCP 0 (best effort) is applied too
+func (so *SockOpts) SetDSCP(dscp byte) {
+	so.SetTOS((dscp & 0x3f) << 2)
+}
+
+func (so *SockOpts) tosSet() bool {
+	return so.TOS > 0 || so.TOSSet
+}
+
+func (so *SockOpts) GetDSCP() byte {
+	return byte(so.TOS>>2) & 0x3f
+}
+
+// Control func for net.Dialer and net.ListenConfig
+func (so *SockOpts) Control(network, address string, c syscall.RawConn) (err error) {
+	if so == nil || (!so.tosSet() && len(so.Device) == 0) {
+		return
+	}
+	v6 := sockIsV6(network, address)
+	cerr := c.Control(func(fd uintptr) {
+		if len(so.Device) != 0 {
+			if err = bindDevice(fd, so.Device); err != nil {
+				return
+			}
+		}
+		if so.tosSet() {
+			err = setTOS(fd, max(so.TOS, 0), v6)
+		}
+	})
+	if cerr != nil {
+		return cerr
+	}
+	return
+}
+
+func sockIsV6(network, address string) bool {
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return false
+	case strings.HasSuffix(network, "6"):
+		return true
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,8 @@
+package radius
+
+import "syscall"
+
+// SO_BINDTODEVICE, works for VRF devices as well
+func bindDevice(fd uintptr, dev string) error {
+	return syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, dev)
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package radius
+
+func bindDevice(fd uintptr, dev string) error {
+	return errSockOptUnsupported
+}
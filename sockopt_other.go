@@ -0,0 +1,7 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package radius
+
+func setTOS(fd uintptr, tos int, v6 bool) error {
+	return errSockOptUnsupported
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package radius
+
+import "syscall"
+
+func setTOS(fd uintptr, tos int, v6 bool) error {
+	if !v6 {
+		return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, tos)
+	}
+	if err := syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS, tos); err != nil {
+		return err
+	}
+	// dual stack socket may carry IPv4 traffic too
+	syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, tos)
+	return nil
+}
@@ -0,0 +1,127 @@
+package radius
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+)
+
+var errStationID = errors.New("Invalid station id format")
+
+// MAC formatting style for Calling-Station-Id/Called-Station-Id
+type MACStyle int
+
+const (
+	MACStyleHyphen MACStyle = iota // 00-11-22-33-44-55 (RFC 3580)
+	MACStyleColon                  // 00:11:22:33:44:55
+	MACStyleDot                    // 0011.2233.4455
+	MACStyleRaw                    // 001122334455
+)
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') && !(c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// length of MAC part in s, 0 if no MAC
+func macPrefix(s string) int {
+	switch {
+	case len(s) >= 17 && (s[2] == ':' || s[2] == '-'):
+		for i := 0; i < 17; i += 3 {
+			if !isHex(s[i:i+2]) || (i < 15 && s[i+2] != s[2]) {
+				return 0
+			}
+		}
+		return 17
+	case len(s) >= 14 && s[4] == '.' && s[9] == '.':
+		if isHex(s[:4]) && isHex(s[5:9]) && isHex(s[10:14]) {
+			return 14
+		}
+	case len(s) >= 12 && isHex(s[:12]):
+		return 12
+	}
+	return 0
+}
+
+// Parse station id as MAC with optional ":SSID" suffix (RFC 3580),
+// separators may be colon, hyphen, dot (Cisco) or none
+func ParseStationID(s string) (mac net.HardwareAddr, ssid string, err error) {
+	s = strings.TrimSpace(s)
+	n := macPrefix(s)
+	if n == 0 {
+		return nil, "", errStationID
+	}
+	switch rest := s[n:]; {
+	case len(rest) == 0:
+	case rest[0] == ':':
+		ssid = rest[1:]
+	default:
+		return nil, "", errStationID
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r == ':' || r == '-' || r == '.' {
+			return -1
+		}
+		return r
+	}, s[:n])
+	if mac, err = hex.DecodeString(digits); err != nil {
+		return nil, "", errStationID
+	}
+	return
+}
+
+// Format MAC with optional SSID as station id
+func FormatStationID(mac net.HardwareAddr, ssid string, style MACStyle, upper bool) string {
+	h := hex.EncodeToString(mac)
+	if upper {
+		h = strings.ToUpper(h)
+	}
+	var b strings.Builder
+	switch style {
+	case MACStyleColon, MACStyleHyphen:
+		sep := byte('-')
+		if style == MACStyleColon {
+			sep = ':'
+		}
+		for i := 0; i < len(h); i += 2 {
+			if i > 0 {
+				b.WriteByte(sep)
+			}
+			b.WriteString(h[i : i+2])
+		}
+	case MACStyleDot:
+		for i := 0; i < len(h); i += 4 {
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(h[i:min(i+4, len(h))])
+		}
+	default:
+		b.WriteString(h)
+	}
+	if len(ssid) != 0 {
+		b.WriteByte(':')
+		b.WriteString(ssid)
+	}
+	return b.String()
+}
+
+func (p *Packet) GetCallingStationMAC() (net.HardwareAddr, string, error) {
+	if a := p.GetAttr(AttrCallingStationID); a != nil {
+		return ParseStationID(string(a.data))
+	}
+	return nil, "", errors.New("Calling-Station-Id not found")
+}
+
+func (p *Packet) GetCalledStationMAC() (net.HardwareAddr, string, error) {
+	if a := p.GetAttr(AttrCalledStationID); a != nil {
+		return ParseStationID(string(a.data))
+	}
+	return nil, "", errors.New("Called-Station-Id not found")
+}
@@ -0,0 +1,158 @@
+package radius
+
+import (
+	"net"
+)
+
+// Setters and getters of common standard attrs, no dictionary is needed.
+// Setter replaces value of first attr of type or adds attr if absent.
+
+// set value of first standard attr of type or add it
+func (p *Packet) setStd(at AttrType, data interface{}) error {
+	if a := p.GetAttr(at); a != nil {
+		return a.Set(data)
+	}
+	return p.addStd(at, 0, data)
+}
+
+func (p *Packet) SetUserName(name string) error {
+	return p.setStd(AttrUserName, name)
+}
+
+func (p *Packet) GetUserName() (string, bool) {
+	return p.GetString(AttrUserName)
+}
+
+// Password is kept in plain text in packet and encrypted on serialize
+func (p *Packet) SetUserPassword(password string) error {
+	return p.setStd(AttrUserPassword, password)
+}
+
+// Plain text password, parsed one is decrypted, see Attr.Decrypt
+func (p *Packet) GetUserPassword() (string, bool) {
+	a := p.GetAttr(AttrUserPassword)
+	if a == nil {
+		return "", false
+	}
+	pw, err := a.Decrypt()
+	if err != nil {
+		return "", false
+	}
+	return string(pw), true
+}
+
+// IPv6 address is set as NAS-IPv6-Address
+func (p *Packet) SetNASIPAddress(ip net.IP) error {
+	if ip.To4() == nil {
+		return p.setStd(AttrNASIPv6Address, ip)
+	}
+	return p.setStd(AttrNASIPAddress, ip)
+}
+
+// NAS-IP-Address, NAS-IPv6-Address if absent
+func (p *Packet) GetNASIPAddress() (net.IP, bool) {
+	if ip, ok := p.GetIP(AttrNASIPAddress); ok {
+		return ip, true
+	}
+	return p.GetIP(AttrNASIPv6Address)
+}
+
+func (p *Packet) SetNASIdentifier(id string) error {
+	return p.setStd(AttrNASIdentifier, id)
+}
+
+func (p *Packet) GetNASIdentifier() (string, bool) {
+	return p.GetString(AttrNASIdentifier)
+}
+
+func (p *Packet) SetNASPort(port uint32) error {
+	return p.setStd(AttrNASPort, port)
+}
+
+func (p *Packet) GetNASPort() (uint32, bool) {
+	return p.GetUint32(AttrNASPort)
+}
+
+func (p *Packet) SetNASPortType(pt uint32) error {
+	return p.setStd(AttrNASPortType, pt)
+}
+
+func (p *Packet) GetNASPortType() (uint32, bool) {
+	return p.GetUint32(AttrNASPortType)
+}
+
+func (p *Packet) SetServiceType(st uint32) error {
+	return p.setStd(AttrServiceType, st)
+}
+
+func (p *Packet) GetServiceType() (uint32, bool) {
+	return p.GetUint32(AttrServiceType)
+}
+
+func (p *Packet) SetFramedIPAddress(ip net.IP) error {
+	return p.setStd(AttrFramedIPAddress, ip)
+}
+
+func (p *Packet) GetFramedIPAddress() (net.IP, bool) {
+	return p.GetIP(AttrFramedIPAddress)
+}
+
+func (p *Packet) SetCallingStationID(id string) error {
+	return p.setStd(AttrCallingStationID, id)
+}
+
+func (p *Packet) GetCallingStationID() (string, bool) {
+	return p.GetString(AttrCallingStationID)
+}
+
+func (p *Packet) SetCalledStationID(id string) error {
+	return p.setStd(AttrCalledStationID, id)
+}
+
+func (p *Packet) GetCalledStationID() (string, bool) {
+	return p.GetString(AttrCalledStationID)
+}
+
+func (p *Packet) SetAcctSessionID(id string) error {
+	return p.setStd(AttrAcctSessionID, id)
+}
+
+func (p *Packet) GetAcctSessionID() (string, bool) {
+	return p.GetString(AttrAcctSessionID)
+}
+
+func (p *Packet) SetAcctStatusType(st uint32) error {
+	return p.setStd(AttrAcctStatusType, st)
+}
+
+func (p *Packet) GetAcctStatusType() (uint32, bool) {
+	return p.GetUint32(AttrAcctStatusType)
+}
+
+func (p *Packet) SetSessionTimeout(sec uint32) error {
+	return p.setStd(AttrSessionTimeout, sec)
+}
+
+func (p *Packet) GetSessionTimeout() (uint32, bool) {
+	return p.GetUint32(AttrSessionTimeout)
+}
+
+func (p *Packet) SetReplyMessage(msg string) error {
+	return p.setStd(AttrReplyMessage, msg)
+}
+
+func (p *Packet) GetReplyMessage() (string, bool) {
+	return p.GetString(AttrReplyMessage)
+}
+
+func (p *Packet) SetState(state []byte) error {
+	return p.setStd(AttrState, state)
+}
+
+func (p *Packet) GetState() ([]byte, bool) {
+	a := p.GetAttr(AttrState)
+	if a == nil {
+		return nil, false
+	}
+	return a.data, true
+}
@@ -0,0 +1,107 @@
+package radius
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// Key/value storage for caches and session state.
+// Implementations must be safe for concurrent use; ttl <= 0 - no expiry.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, val []byte, ttl time.Duration)
+	Delete(key string)
+	// Atomically replace value if it is still old (nil - key is missing),
+	// nil val deletes key. False if value was changed by someone else.
+	CompareAndSwap(key string, old, val []byte, ttl time.Duration) bool
+}
+
+type memItem struct {
+	val []byte
+	exp time.Time // zero - no expiry
+}
+
+// In-memory Store, expired items are dropped on access and by Cleanup
+type MemStore struct {
+	mu    sync.Mutex
+	items map[string]memItem
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{
+		items: make(map[string]memItem),
+	}
+}
+
+func (ms *MemStore) Get(key string) ([]byte, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	it, ok := ms.items[key]
+	if !ok {
+		return nil, false
+	}
+	if !it.exp.IsZero() && time.Now().After(it.exp) {
+		delete(ms.items, key)
+		return nil, false
+	}
+	return it.val, true
+}
+
+func (ms *MemStore) Set(key string, val []byte, ttl time.Duration) {
+	it := memItem{val: val}
+	if ttl > 0 {
+		it.exp = time.Now().Add(ttl)
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.items[key] = it
+}
+
+func (ms *MemStore) CompareAndSwap(key string, old, val []byte, ttl time.Duration) bool {
+	now := time.Now()
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	it, ok := ms.items[key]
+	if ok && !it.exp.IsZero() && now.After(it.exp) {
+		delete(ms.items, key)
+		ok = false
+	}
+	if ok != (old != nil) || !bytes.Equal(it.val, old) {
+		return false
+	}
+	if val == nil {
+		delete(ms.items, key)
+		return true
+	}
+	it = memItem{val: val}
+	if ttl > 0 {
+		it.exp = now.Add(ttl)
+	}
+	ms.items[key] = it
+	return true
+}
+
+func (ms *MemStore) Delete(key string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.items, key)
+}
+
+// Drop all expired items
+func (ms *MemStore) Cleanup() {
+	now := time.Now()
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for k, it := range ms.items {
+		if !it.exp.IsZero() && now.After(it.exp) {
+			delete(ms.items, k)
+		}
+	}
+}
+
+func (ms *MemStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.items)
+}
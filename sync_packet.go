@@ -0,0 +1,72 @@
+package radius
+
+import "sync"
+
+// Packet shared between goroutines.
+// Readers use View, writers use Update; a policy layer that needs its own
+// changes can take a private Copy instead. Decoded values are evaluated
+// after every change, so concurrent GetEData inside View does not race on
+// the decoded value cache.
+type SyncPacket struct {
+	mu sync.RWMutex
+	p  *Packet
+}
+
+func NewSyncPacket(p *Packet) *SyncPacket {
+	p.evalAll()
+	return &SyncPacket{p: p}
+}
+
+// Read packet under shared lock, fn must not modify packet
+func (sp *SyncPacket) View(fn func(p *Packet)) {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	fn(sp.p)
+}
+
+// Modify packet under exclusive lock
+func (sp *SyncPacket) Update(fn func(p *Packet) error) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	err := fn(sp.p)
+	sp.p.evalAll()
+	return err
+}
+
+// Private copy of packet, changes to it are not visible to others
+func (sp *SyncPacket) Copy() *Packet {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.p.shallowCopy()
+}
+
+// fill decoded value cache for all attrs
+func (p *Packet) evalAll() {
+	if p == nil {
+		return
+	}
+	for _, a := range p.attrList() {
+		a.GetEData()
+	}
+}
+
+// copy of packet and attrs, attr data is shared
+func (p *Packet) shallowCopy() *Packet {
+	if p == nil {
+		return nil
+	}
+	np := *p
+	np.attrs = make([]*Attr, len(p.attrs))
+	for i, a := range p.attrs {
+		na := *a
+		na.pkt = &np
+		np.attrs[i] = &na
+	}
+	np.vids = append([]VendorID(nil), p.vids...)
+	np.vset = make(vendorSet, len(p.vset))
+	for v := range p.vset {
+		np.vset[v] = struct{}{}
+	}
+	np.tdata = p.tdata.copy()
+	return &np
+}
@@ -0,0 +1,197 @@
+package radius
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// RFC 6929 TLV, value of TLV of tlv type known to dictionary is parsed
+// in TLVs, value of other TLVs is in Data
+type TLV struct {
+	Type byte
+	Data []byte
+	TLVs []TLV
+}
+
+var (
+	errTLVLen  = errors.New("TLV too long")
+	errTLVPath = errors.New("Invalid TLV path")
+)
+
+// Put nested TLV in dictionary, path is dotted types from attr down to TLV,
+// like 241.5.1 or 26.9.1.3, parent TLVs must be of DTypeTLV
+func AddTLV(name, path string, dtype AttrDType) error {
+	parts, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	if len(parts) < 2 {
+		return errTLVPath
+	}
+	attrDict.Lock()
+	defer attrDict.Unlock()
+	if _, ok := attrDict.byPath[path]; ok {
+		return errors.New("TLV exists: " + name)
+	}
+	attrDict.byPath[path] = &AttrData{
+		name:  name,
+		atype: AttrType(parts[0]),
+		dtype: dtype,
+		path:  path,
+	}
+	return nil
+}
+
+func MustAddTLV(name, path string, dtype AttrDType) {
+	if err := AddTLV(name, path, dtype); err != nil {
+		panic(err)
+	}
+}
+
+func GetTLVByPath(path string) *AttrData {
+	attrDict.RLock()
+	defer attrDict.RUnlock()
+	return attrDict.byPath[path]
+}
+
+// dotted path of attr, 26.V.T for VSA, 241.N for extended attr, 241.26.V.T for EVS
+func (ad *AttrData) attrPath() string {
+	switch {
+	case ad.path != "":
+		return ad.path
+	case ad.atype == AttrVSA:
+		return strconv.Itoa(int(ad.atype)) + "." + strconv.Itoa(int(ad.vid)) + "." + strconv.Itoa(int(ad.vtype))
+	case isExtended(ad.atype) && ad.vid != 0:
+		return strconv.Itoa(int(ad.atype)) + "." + strconv.Itoa(int(ExtEVS)) + "." + strconv.Itoa(int(ad.vid)) + "." + strconv.Itoa(int(ad.vtype))
+	case isExtended(ad.atype):
+		return strconv.Itoa(int(ad.atype)) + "." + strconv.Itoa(int(ad.vtype))
+	}
+	return strconv.Itoa(int(ad.atype))
+}
+
+func splitPath(path string) ([]uint32, error) {
+	ss := strings.Split(path, ".")
+	parts := make([]uint32, len(ss))
+	for i, s := range ss {
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, errTLVPath
+		}
+		parts[i] = uint32(n)
+	}
+	return parts, nil
+}
+
+// Parse TLVs of value, TLVs of tlv type under path are parsed recursively
+func decodeTLVs(b []byte, path string) ([]TLV, bool) {
+	var tlvs []TLV
+	rb := rBuf{buf: b, bl: len(b)}
+	for rb.getLeft() > 0 {
+		t, v, err := rb.getAttr()
+		if err != nil {
+			return nil, false
+		}
+		tp := path + "." + strconv.Itoa(int(t))
+		tlv := TLV{Type: t, Data: v}
+		if GetTLVByPath(tp).GetDataType() == DTypeTLV {
+			if tlv.TLVs, _ = decodeTLVs(v, tp); tlv.TLVs != nil {
+				tlv.Data = nil
+			}
+		}
+		tlvs = append(tlvs, tlv)
+	}
+	return tlvs, true
+}
+
+// len of TLV value
+func (t *TLV) valueLen() int {
+	if t.TLVs == nil {
+		return len(t.Data)
+	}
+	n := 0
+	for i := range t.TLVs {
+		n += t.TLVs[i].valueLen() + 2
+	}
+	return n
+}
+
+func appendTLVs(b []byte, tlvs []TLV) ([]byte, error) {
+	for i := range tlvs {
+		t := &tlvs[i]
+		n := t.valueLen()
+		if n > 253 {
+			return b, errTLVLen
+		}
+		b = append(b, t.Type, byte(n+2))
+		if t.TLVs == nil {
+			b = append(b, t.Data...)
+			continue
+		}
+		var err error
+		if b, err = appendTLVs(b, t.TLVs); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+// value of first TLV of type in b, nil if absent or b is not TLVs
+func findTLV(b []byte, t byte) []byte {
+	rb := rBuf{buf: b, bl: len(b)}
+	for rb.getLeft() > 0 {
+		tt, v, err := rb.getAttr()
+		if err != nil {
+			return nil
+		}
+		if tt == t {
+			return v
+		}
+	}
+	return nil
+}
+
+// Raw value by dotted path: attr type, vendor ID and type for VSA or
+// extended type for extended attr, followed by vendor ID and type for EVS,
+// then types of nested TLVs, like 241.5.1.3, 26.9.1 or 241.26.9.1.
+// First attr and TLV of type is taken on each level.
+func (p *Packet) GetByPath(path string) ([]byte, bool) {
+	parts, err := splitPath(path)
+	if err != nil || parts[0] > 0xff {
+		return nil, false
+	}
+	at := AttrType(parts[0])
+	var a *Attr
+	switch {
+	case at == AttrVSA:
+		if len(parts) < 3 || parts[2] > 0xffff {
+			return nil, false
+		}
+		a, parts = p.GetVSA(VendorID(parts[1]), VendorType(parts[2])), parts[3:]
+	case isExtended(at) && len(parts) > 1 && parts[1] == uint32(ExtEVS):
+		if len(parts) < 4 || parts[3] > 0xff {
+			return nil, false
+		}
+		a, parts = p.GetEVS(at, VendorID(parts[2]), VendorType(parts[3])), parts[4:]
+	case isExtended(at):
+		if len(parts) < 2 || parts[1] > 0xff {
+			return nil, false
+		}
+		a, parts = p.GetExt(at, VendorType(parts[1])), parts[2:]
+	default:
+		a, parts = p.GetAttr(at), parts[1:]
+	}
+	if a == nil {
+		return nil, false
+	}
+	data := a.data
+	for _, t := range parts {
+		if t > 0xff {
+			return nil, false
+		}
+		if data = findTLV(data, byte(t)); data == nil {
+			return nil, false
+		}
+	}
+	return data, true
+}
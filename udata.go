@@ -0,0 +1,51 @@
+package radius
+
+// Typed user data, one value per type
+type typedData map[interface{}]interface{}
+
+type udataKey[T any] struct{}
+
+func (td typedData) copy() typedData {
+	if len(td) == 0 {
+		return nil
+	}
+	r := make(typedData, len(td))
+	for k, v := range td {
+		r[k] = v
+	}
+	return r
+}
+
+// Get user data set by SetUserData as T
+func UserData[T any](p *Packet) (v T, ok bool) {
+	v, ok = p.GetUserData().(T)
+	return
+}
+
+// Store value of type T in packet, replacing previous value of same type
+func SetValue[T any](p *Packet, v T) {
+	if p == nil {
+		return
+	}
+	if p.tdata == nil {
+		p.tdata = make(typedData)
+	}
+	p.tdata[udataKey[T]{}] = v
+}
+
+// Get value of type T stored by SetValue
+func Value[T any](p *Packet) (v T, ok bool) {
+	if p == nil {
+		return
+	}
+	v, ok = p.tdata[udataKey[T]{}].(T)
+	return
+}
+
+// Remove value of type T from packet
+func DelValue[T any](p *Packet) {
+	if p == nil {
+		return
+	}
+	delete(p.tdata, udataKey[T]{})
+}
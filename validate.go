@@ -0,0 +1,222 @@
+package radius
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Attrs which may be present at most once (RFC 2865 5.44, RFC 2866 5.13)
+var singleAttrs = map[AttrType]bool{
+	AttrUserName:             true,
+	AttrUserPassword:         true,
+	AttrCHAPPassword:         true,
+	AttrNASIPAddress:         true,
+	AttrNASPort:              true,
+	AttrServiceType:          true,
+	AttrFramedProtocol:       true,
+	AttrFramedIPAddress:      true,
+	AttrFramedIPNetmask:      true,
+	AttrFramedRouting:        true,
+	AttrFramedMTU:            true,
+	AttrLoginTCPPort:         true,
+	AttrCallbackNumber:       true,
+	AttrCallbackID:           true,
+	AttrFramedIPXNetwork:     true,
+	AttrState:                true,
+	AttrSessionTimeout:       true,
+	AttrIdleTimeout:          true,
+	AttrTerminationAction:    true,
+	AttrCalledStationID:      true,
+	AttrCallingStationID:     true,
+	AttrNASIdentifier:        true,
+	AttrAcctStatusType:       true,
+	AttrAcctDelayTime:        true,
+	AttrAcctInputOctets:      true,
+	AttrAcctOutputOctets:     true,
+	AttrAcctSessionID:        true,
+	AttrAcctAuthentic:        true,
+	AttrAcctSessionTime:      true,
+	AttrAcctInputPackets:     true,
+	AttrAcctOutputPackets:    true,
+	AttrAcctTerminateCause:   true,
+	AttrAcctMultiSessionID:   true,
+	AttrAcctLinkCount:        true,
+	AttrAcctInputGigawords:   true,
+	AttrAcctOutputGigawords:  true,
+	AttrEventTimestamp:       true,
+	AttrCHAPChallenge:        true,
+	AttrNASPortType:          true,
+	AttrPortLimit:            true,
+	AttrMessageAuthenticator: true,
+	AttrAcctInterimInterval:  true,
+	AttrNASPortID:            true,
+	AttrNASIPv6Address:       true,
+	AttrFramedInterfaceID:    true,
+	AttrErrorCause:           true,
+}
+
+var (
+	errAttrSize  = errors.New("Attribute len does not match dictionary")
+	errAttrCount = errors.New("Attribute occurs too many times")
+)
+
+// Set fixed value len and max occurrences in packet of dictionary attr,
+// 0 - no limit. Builtin standard attr is put in dictionary first.
+// Limits are enforced by AddAttr and strict ParsePacketOpts, and reported
+// by Validate.
+func SetAttrLimits(name string, size, max int) error {
+	if size < 0 || size > 253 || max < 0 {
+		return errors.New("Invalid limits: " + name)
+	}
+	attrDict.Lock()
+	defer attrDict.Unlock()
+	ad := dictAttr(name)
+	if ad == nil {
+		return errors.New("Attribute not found: " + name)
+	}
+	ad.size, ad.max = size, max
+	return nil
+}
+
+func MustSetAttrLimits(name string, size, max int) {
+	if err := SetAttrLimits(name, size, max); err != nil {
+		panic(err)
+	}
+}
+
+// value len for fixed size types, 0 - variable
+func dtypeLen(dt AttrDType) int {
+	switch dt {
+	case DTypeByte:
+		return 1
+	case DTypeShort:
+		return 2
+	case DTypeIP4, DTypeInt, DTypeDate, DTypeSInt, DTypeSNTP:
+		return 4
+	case DTypeIP4Pfx, DTypeEth:
+		return 6
+	case DTypeInt64, DTypeIfID:
+		return 8
+	case DTypeIP6:
+		return 16
+	}
+	return 0
+}
+
+// value len, for fixed size tagged types tag is part of value
+func (a *Attr) valueLen() int {
+	if a.ad.IsTagged() && dtypeLen(a.ad.dtype) != 0 {
+		return len(a.data) + 1
+	}
+	return len(a.data)
+}
+
+// attrs in packet with the same attr data
+func (p *Packet) countAttrs(ad *AttrData) (n int) {
+	for _, a := range p.attrList() {
+		if a.attrData() == ad {
+			n++
+		}
+	}
+	return
+}
+
+// check dictionary len and occurrence limits, first violation is returned
+func (p *Packet) checkLimits() error {
+	var counts map[*AttrData]int
+	for _, a := range p.attrList() {
+		ad := a.attrData()
+		if ad.GetSize() != 0 && a.valueLen() != ad.size {
+			return errAttrSize
+		}
+		if ad.GetMaxCount() == 0 {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[*AttrData]int)
+		}
+		if counts[ad]++; counts[ad] > ad.max {
+			return errAttrCount
+		}
+	}
+	return nil
+}
+
+// check single attr
+func (a *Attr) validate() error {
+	if len(a.data) == 0 && !a.ad.IsTagged() {
+		return fmt.Errorf("%s: empty value", a.GetName())
+	}
+	if n := a.wireLen(); a.tooLong() {
+		return fmt.Errorf("%s: len %d exceeds 255", a.GetName(), n)
+	}
+	if a.ad == nil {
+		return nil
+	}
+	if a.ad.IsTagged() && a.tag > 0x1f {
+		return fmt.Errorf("%s: tag %d out of range", a.GetName(), a.tag)
+	}
+	vl := a.valueLen()
+	switch {
+	case a.ad.size != 0:
+		if vl != a.ad.size {
+			return fmt.Errorf("%s: len %d, must be %d", a.GetName(), vl, a.ad.size)
+		}
+	case a.atype == AttrMessageAuthenticator:
+		if vl != authLen {
+			return fmt.Errorf("%s: len %d, must be %d", a.GetName(), vl, authLen)
+		}
+	case a.ad.dtype == DTypeIP6Pfx:
+		if vl < 2 || vl > 18 {
+			return fmt.Errorf("%s: len %d out of range", a.GetName(), vl)
+		}
+	case dtypeLen(a.ad.dtype) != 0:
+		if vl != dtypeLen(a.ad.dtype) {
+			return fmt.Errorf("%s: len %d, must be %d", a.GetName(), vl, dtypeLen(a.ad.dtype))
+		}
+	}
+	return nil
+}
+
+// Strict RFC checks: header len vs buffer, attr len bounds, empty attrs,
+// duplicates of single attrs and tag ranges. Returns all violations found,
+// nil if packet is valid, so caller can reject or only log them.
+func (p *Packet) Validate() (errs []error) {
+	if p == nil {
+		return []error{errors.New("Packet empty")}
+	}
+	if p.data != nil {
+		if _, err := checkHeader(p.data, MaxLongLen); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n := MinPLen + p.attrsLen(); n > p.getMaxLen() {
+		errs = append(errs, fmt.Errorf("Packet len %d exceeds %d", n, p.getMaxLen()))
+	}
+	var (
+		seen   attrMap
+		counts map[*AttrData]int
+	)
+	for _, a := range p.attrList() {
+		if err := a.validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if ad := a.attrData(); ad.GetMaxCount() != 0 { // dictionary limit overrides RFC one
+			if counts == nil {
+				counts = make(map[*AttrData]int)
+			}
+			if counts[ad]++; counts[ad] == ad.max+1 {
+				errs = append(errs, fmt.Errorf("%s: more than %d attrs", a.GetName(), ad.max))
+			}
+			continue
+		}
+		if !singleAttrs[a.atype] {
+			continue
+		}
+		if seen.has(a.atype) {
+			errs = append(errs, fmt.Errorf("%s: duplicate attr", a.GetName()))
+		}
+		seen.set(a.atype)
+	}
+	return
+}
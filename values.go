@@ -0,0 +1,236 @@
+package radius
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Named values of integer attrs (FreeRADIUS VALUE), keyed by attr key
+type attrValues struct {
+	byName  map[string]uint32
+	byValue map[uint32]string
+}
+
+func newAttrValues() *attrValues {
+	return &attrValues{
+		byName:  make(map[string]uint32),
+		byValue: make(map[uint32]string),
+	}
+}
+
+// first name of value is kept for output, all names are accepted on input
+func (av *attrValues) add(name string, v uint32) {
+	av.byName[nameKey(name)] = v
+	if _, ok := av.byValue[v]; !ok {
+		av.byValue[v] = name
+	}
+}
+
+func rfcValues(vals map[string]uint32) *attrValues {
+	av := newAttrValues()
+	for name, v := range vals {
+		av.add(name, v)
+	}
+	return av
+}
+
+// Builtin values of standard attrs
+var stdValues = map[uint64]*attrValues{
+	uint64(AttrServiceType): rfcValues(map[string]uint32{
+		"Login-User":               1,
+		"Framed-User":              2,
+		"Callback-Login-User":      3,
+		"Callback-Framed-User":     4,
+		"Outbound-User":            5,
+		"Administrative-User":      6,
+		"NAS-Prompt-User":          7,
+		"Authenticate-Only":        8,
+		"Callback-NAS-Prompt":      9,
+		"Call-Check":               10,
+		"Callback-Administrative":  11,
+		"Authorize-Only":           17,
+		"Framed-Management":        18,
+		"Additional-Authorization": serviceAddAuth,
+	}),
+	uint64(AttrFramedProtocol): rfcValues(map[string]uint32{
+		"PPP":              1,
+		"SLIP":             2,
+		"ARAP":             3,
+		"GDLP":             4,
+		"IPX":              5,
+		"GPRS-PDP-Context": 7,
+	}),
+	uint64(AttrAcctStatusType): rfcValues(map[string]uint32{
+		"Start":          AcctStatusStart,
+		"Stop":           AcctStatusStop,
+		"Interim-Update": AcctStatusInterim,
+		"Accounting-On":  AcctStatusOn,
+		"Accounting-Off": AcctStatusOff,
+	}),
+	uint64(AttrAcctAuthentic): rfcValues(map[string]uint32{
+		"RADIUS":   1,
+		"Local":    2,
+		"Remote":   3,
+		"Diameter": 4,
+	}),
+	uint64(AttrNASPortType): rfcValues(map[string]uint32{
+		"Async":              0,
+		"Sync":               1,
+		"ISDN":               2,
+		"ISDN-V120":          3,
+		"ISDN-V110":          4,
+		"Virtual":            5,
+		"PIAFS":              6,
+		"HDLC-Clear-Channel": 7,
+		"X.25":               8,
+		"X.75":               9,
+		"G.3-Fax":            10,
+		"SDSL":               11,
+		"ADSL-CAP":           12,
+		"ADSL-DMT":           13,
+		"IDSL":               14,
+		"Ethernet":           15,
+		"xDSL":               16,
+		"Cable":              17,
+		"Wireless-Other":     18,
+		"Wireless-802.11":    19,
+	}),
+	uint64(AttrAcctTerminateCause): rfcValues(map[string]uint32{
+		"User-Request":        TermUserRequest,
+		"Lost-Carrier":        TermLostCarrier,
+		"Lost-Service":        TermLostService,
+		"Idle-Timeout":        TermIdleTimeout,
+		"Session-Timeout":     TermSessionTimeout,
+		"Admin-Reset":         TermAdminReset,
+		"Admin-Reboot":        TermAdminReboot,
+		"Port-Error":          TermPortError,
+		"NAS-Error":           TermNASError,
+		"NAS-Request":         TermNASRequest,
+		"NAS-Reboot":          TermNASReboot,
+		"Port-Unneeded":       TermPortUnneeded,
+		"Port-Preempted":      TermPortPreempted,
+		"Port-Suspended":      TermPortSuspended,
+		"Service-Unavailable": TermServiceUnavailable,
+		"Callback":            TermCallback,
+		"User-Error":          TermUserError,
+		"Host-Request":        TermHostRequest,
+	}),
+}
+
+// value names of attr, dictionary values override builtin ones
+func (ad *AttrData) values() (dv, sv *attrValues) {
+	if ad == nil || ad.path != "" {
+		return
+	}
+	key := attrKey(ad.atype, ad.vid, ad.vtype)
+	attrDict.RLock()
+	dv = attrDict.values[key]
+	attrDict.RUnlock()
+	return dv, stdValues[key]
+}
+
+// Value of named value of attr
+func (ad *AttrData) GetValue(name string) (uint32, bool) {
+	dv, sv := ad.values()
+	nKey := nameKey(name)
+	if dv != nil {
+		attrDict.RLock()
+		v, ok := dv.byName[nKey]
+		attrDict.RUnlock()
+		if ok {
+			return v, true
+		}
+	}
+	if sv != nil {
+		v, ok := sv.byName[nKey]
+		return v, ok
+	}
+	return 0, false
+}
+
+// Name of value of attr, empty if value has no name
+func (ad *AttrData) GetValueName(v uint32) string {
+	dv, sv := ad.values()
+	if dv != nil {
+		attrDict.RLock()
+		name, ok := dv.byValue[v]
+		attrDict.RUnlock()
+		if ok {
+			return name
+		}
+	}
+	if sv != nil {
+		return sv.byValue[v]
+	}
+	return ""
+}
+
+// Put named value of integer attr in dictionary (FreeRADIUS VALUE),
+// attr is known to dictionary or builtin standard attr
+func AddValue(attrName, name string, v uint32) error {
+	ad := stdAttrByName(attrName)
+	switch {
+	case ad == nil:
+		return errors.New("Attribute not found: " + attrName)
+	case ad.path != "":
+		return errors.New("Values of nested TLV not supported: " + attrName)
+	}
+	switch ad.dtype {
+	case DTypeInt, DTypeInt64, DTypeShort, DTypeByte:
+	default:
+		return errors.New("Not integer attribute: " + attrName)
+	}
+	key := attrKey(ad.atype, ad.vid, ad.vtype)
+	attrDict.Lock()
+	defer attrDict.Unlock()
+	av, ok := attrDict.values[key]
+	if !ok {
+		av = newAttrValues()
+		attrDict.values[key] = av
+	}
+	av.add(name, v)
+	return nil
+}
+
+func MustAddValue(attrName, name string, v uint32) {
+	if err := AddValue(attrName, name, v); err != nil {
+		panic(err)
+	}
+}
+
+// value name in place of string value of integer attr
+func valueConv(ad *AttrData, v interface{}) ([]byte, error) {
+	if s, ok := v.(string); ok {
+		switch ad.dtype {
+		case DTypeInt, DTypeInt64, DTypeShort, DTypeByte:
+			n, ok := ad.GetValue(s)
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown value %q", ad.name, s)
+			}
+			v = n
+		}
+	}
+	return attrConv(ad.dtype, v)
+}
+
+// Name of integer attr value, empty if value has no name
+func (a *Attr) GetValueName() string {
+	var n uint32
+	switch v := a.GetEData().(type) {
+	case uint32:
+		n = v
+	case uint16:
+		n = uint32(v)
+	case byte:
+		n = uint32(v)
+	case uint64:
+		if v > math.MaxUint32 {
+			return ""
+		}
+		n = uint32(v)
+	default:
+		return ""
+	}
+	return a.attrData().GetValueName(n)
+}
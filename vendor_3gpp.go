@@ -0,0 +1,324 @@
+package radius
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	Vendor3GPP               VendorID   = 10415
+	ThreeGPPUserLocationInfo VendorType = 22
+	ThreeGPPMSTimeZone       VendorType = 23
+)
+
+var errULI = errors.New("Invalid 3GPP-User-Location-Info")
+
+// Geographic Location Type (TS 29.061)
+type ULIType byte
+
+const (
+	ULITypeCGI     ULIType = 0
+	ULITypeSAI     ULIType = 1
+	ULITypeRAI     ULIType = 2
+	ULITypeTAI     ULIType = 128
+	ULITypeECGI    ULIType = 129
+	ULITypeTAIECGI ULIType = 130
+)
+
+// MCC and MNC as decimal strings
+type PLMN struct {
+	MCC string
+	MNC string
+}
+
+type CGI struct {
+	PLMN
+	LAC uint16
+	CI  uint16
+}
+
+type SAI struct {
+	PLMN
+	LAC uint16
+	SAC uint16
+}
+
+type RAI struct {
+	PLMN
+	LAC uint16
+	RAC byte
+}
+
+type TAI struct {
+	PLMN
+	TAC uint16
+}
+
+type ECGI struct {
+	PLMN
+	ECI uint32 // 28 bit
+}
+
+// Decoded 3GPP-User-Location-Info, only fields for Type are set
+type UserLocation struct {
+	Type ULIType
+	CGI  *CGI
+	SAI  *SAI
+	RAI  *RAI
+	TAI  *TAI
+	ECGI *ECGI
+}
+
+func bcdDigit(d byte) (byte, bool) {
+	if d > 9 {
+		return 0, false
+	}
+	return '0' + d, true
+}
+
+// decode 3 octets of TBCD PLMN
+func decodePLMN(b []byte) (pl PLMN, err error) {
+	var mcc, mnc [3]byte
+	var ok bool
+
+	for i, d := range []byte{b[0] & 0x0f, b[0] >> 4, b[1] & 0x0f} {
+		if mcc[i], ok = bcdDigit(d); !ok {
+			return pl, errULI
+		}
+	}
+	n := 2
+	for i, d := range []byte{b[2] & 0x0f, b[2] >> 4, b[1] >> 4} {
+		if i == 2 && d == 0x0f { // 2 digit MNC
+			break
+		}
+		if mnc[i], ok = bcdDigit(d); !ok {
+			return pl, errULI
+		}
+		n = i + 1
+	}
+	pl.MCC = string(mcc[:])
+	pl.MNC = string(mnc[:n])
+	return
+}
+
+func digitsOK(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (pl PLMN) append(b []byte) ([]byte, error) {
+	if !digitsOK(pl.MCC, 3, 3) || !digitsOK(pl.MNC, 2, 3) {
+		return b, errULI
+	}
+	mnc3 := byte(0x0f)
+	if len(pl.MNC) == 3 {
+		mnc3 = pl.MNC[2] - '0'
+	}
+	return append(b,
+		(pl.MCC[1]-'0')<<4|(pl.MCC[0]-'0'),
+		mnc3<<4|(pl.MCC[2]-'0'),
+		(pl.MNC[1]-'0')<<4|(pl.MNC[0]-'0'),
+	), nil
+}
+
+func (pl PLMN) String() string {
+	return pl.MCC + "-" + pl.MNC
+}
+
+func decodeTAI(b []byte) (*TAI, error) {
+	pl, err := decodePLMN(b)
+	if err != nil {
+		return nil, err
+	}
+	return &TAI{PLMN: pl, TAC: binary.BigEndian.Uint16(b[3:])}, nil
+}
+
+func decodeECGI(b []byte) (*ECGI, error) {
+	pl, err := decodePLMN(b)
+	if err != nil {
+		return nil, err
+	}
+	return &ECGI{PLMN: pl, ECI: binary.BigEndian.Uint32(b[3:]) & 0x0fffffff}, nil
+}
+
+func DecodeUserLocation(b []byte) (ul *UserLocation, err error) {
+	if len(b) < 1 {
+		return nil, errULI
+	}
+	ul = &UserLocation{Type: ULIType(b[0])}
+	b = b[1:]
+	switch ul.Type {
+	case ULITypeCGI, ULITypeSAI:
+		if len(b) != 7 {
+			return nil, errULI
+		}
+		var pl PLMN
+		if pl, err = decodePLMN(b); err != nil {
+			return nil, err
+		}
+		lac := binary.BigEndian.Uint16(b[3:])
+		v := binary.BigEndian.Uint16(b[5:])
+		if ul.Type == ULITypeCGI {
+			ul.CGI = &CGI{PLMN: pl, LAC: lac, CI: v}
+		} else {
+			ul.SAI = &SAI{PLMN: pl, LAC: lac, SAC: v}
+		}
+	case ULITypeRAI:
+		if len(b) != 6 && len(b) != 7 { // RAC may be followed by spare octet
+			return nil, errULI
+		}
+		var pl PLMN
+		if pl, err = decodePLMN(b); err != nil {
+			return nil, err
+		}
+		ul.RAI = &RAI{PLMN: pl, LAC: binary.BigEndian.Uint16(b[3:]), RAC: b[5]}
+	case ULITypeTAI:
+		if len(b) != 5 {
+			return nil, errULI
+		}
+		if ul.TAI, err = decodeTAI(b); err != nil {
+			return nil, err
+		}
+	case ULITypeECGI:
+		if len(b) != 7 {
+			return nil, errULI
+		}
+		if ul.ECGI, err = decodeECGI(b); err != nil {
+			return nil, err
+		}
+	case ULITypeTAIECGI:
+		if len(b) != 12 {
+			return nil, errULI
+		}
+		if ul.TAI, err = decodeTAI(b); err != nil {
+			return nil, err
+		}
+		if ul.ECGI, err = decodeECGI(b[5:]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported 3GPP location type: %d", ul.Type)
+	}
+	return
+}
+
+func (ul *UserLocation) Encode() (b []byte, err error) {
+	b = []byte{byte(ul.Type)}
+	switch {
+	case ul.Type == ULITypeCGI && ul.CGI != nil:
+		if b, err = ul.CGI.PLMN.append(b); err != nil {
+			return nil, err
+		}
+		b = binary.BigEndian.AppendUint16(b, ul.CGI.LAC)
+		return binary.BigEndian.AppendUint16(b, ul.CGI.CI), nil
+	case ul.Type == ULITypeSAI && ul.SAI != nil:
+		if b, err = ul.SAI.PLMN.append(b); err != nil {
+			return nil, err
+		}
+		b = binary.BigEndian.AppendUint16(b, ul.SAI.LAC)
+		return binary.BigEndian.AppendUint16(b, ul.SAI.SAC), nil
+	case ul.Type == ULITypeRAI && ul.RAI != nil:
+		if b, err = ul.RAI.PLMN.append(b); err != nil {
+			return nil, err
+		}
+		b = binary.BigEndian.AppendUint16(b, ul.RAI.LAC)
+		return append(b, ul.RAI.RAC), nil
+	case ul.Type == ULITypeTAI && ul.TAI != nil:
+		return ul.TAI.append(b)
+	case ul.Type == ULITypeECGI && ul.ECGI != nil:
+		return ul.ECGI.append(b)
+	case ul.Type == ULITypeTAIECGI && ul.TAI != nil && ul.ECGI != nil:
+		if b, err = ul.TAI.append(b); err != nil {
+			return nil, err
+		}
+		return ul.ECGI.append(b)
+	}
+	return nil, errULI
+}
+
+func (t *TAI) append(b []byte) ([]byte, error) {
+	b, err := t.PLMN.append(b)
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint16(b, t.TAC), nil
+}
+
+func (e *ECGI) append(b []byte) ([]byte, error) {
+	b, err := e.PLMN.append(b)
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(b, e.ECI&0x0fffffff), nil
+}
+
+// 3GPP-MS-TimeZone, offset from UTC and DST adjustment in hours (0-2)
+type MSTimeZone struct {
+	Offset time.Duration
+	DST    byte
+}
+
+func DecodeMSTimeZone(b []byte) (tz MSTimeZone, err error) {
+	if len(b) != 2 {
+		return tz, errors.New("Invalid 3GPP-MS-TimeZone")
+	}
+	// swapped semi-octets, sign in bit 3 of tens digit (TS 24.008)
+	tens := b[0] & 0x07
+	units := b[0] >> 4
+	if units > 9 {
+		return tz, errors.New("Invalid 3GPP-MS-TimeZone")
+	}
+	tz.Offset = time.Duration(tens*10+units) * 15 * time.Minute
+	if b[0]&0x08 != 0 {
+		tz.Offset = -tz.Offset
+	}
+	tz.DST = b[1] & 0x03
+	return
+}
+
+func (tz MSTimeZone) Encode() []byte {
+	q := tz.Offset / (15 * time.Minute)
+	var sign byte
+	if q < 0 {
+		q = -q
+		sign = 0x08
+	}
+	return []byte{byte(q%10)<<4 | byte(q/10)&0x07 | sign, tz.DST & 0x03}
+}
+
+// Zone with offset of time zone
+func (tz MSTimeZone) Location() *time.Location {
+	return time.FixedZone("", int(tz.Offset/time.Second))
+}
+
+func (p *Packet) getVSAData(vid VendorID, vtype VendorType) ([]byte, bool) {
+	if a := p.GetVSA(vid, vtype); a != nil {
+		return a.data, true
+	}
+	return nil, false
+}
+
+func (p *Packet) GetUserLocation() (*UserLocation, error) {
+	b, ok := p.getVSAData(Vendor3GPP, ThreeGPPUserLocationInfo)
+	if !ok {
+		return nil, errors.New("3GPP-User-Location-Info not found")
+	}
+	return DecodeUserLocation(b)
+}
+
+func (p *Packet) GetMSTimeZone() (MSTimeZone, error) {
+	b, ok := p.getVSAData(Vendor3GPP, ThreeGPPMSTimeZone)
+	if !ok {
+		return MSTimeZone{}, errors.New("3GPP-MS-TimeZone not found")
+	}
+	return DecodeMSTimeZone(b)
+}
@@ -0,0 +1,95 @@
+package radius
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	VendorCisco VendorID   = 9
+	CiscoAVPair VendorType = 1
+)
+
+// Cisco-AVPair "key=value" (mandatory) or "key*value" (optional),
+// key may carry protocol prefix like "lcp:interface-config" or "subscriber:command"
+type AVPair struct {
+	Key      string
+	Value    string
+	Optional bool
+}
+
+func ParseAVPair(s string) (av AVPair, ok bool) {
+	i := strings.IndexAny(s, "=*")
+	if i <= 0 {
+		return
+	}
+	av.Key = s[:i]
+	av.Value = s[i+1:]
+	av.Optional = s[i] == '*'
+	ok = true
+	return
+}
+
+func (av AVPair) String() string {
+	if av.Optional {
+		return av.Key + "*" + av.Value
+	}
+	return av.Key + "=" + av.Value
+}
+
+// Protocol prefix of key ("lcp" for "lcp:interface-config"), empty if none
+func (av AVPair) Protocol() string {
+	if i := strings.IndexByte(av.Key, ':'); i > 0 {
+		return av.Key[:i]
+	}
+	return ""
+}
+
+// All well formed Cisco-AVPairs in packet order
+func (p *Packet) GetAVPairs() (r []AVPair) {
+	if p == nil || !p.HasVendor(VendorCisco) {
+		return
+	}
+	for _, a := range p.attrList() {
+		if a.IsVSA() && a.vid == VendorCisco && a.vtype == CiscoAVPair {
+			if av, ok := ParseAVPair(string(a.data)); ok {
+				r = append(r, av)
+			}
+		}
+	}
+	return
+}
+
+// First value for key
+func (p *Packet) GetAVPair(key string) (string, bool) {
+	for _, av := range p.GetAVPairs() {
+		if av.Key == key {
+			return av.Value, true
+		}
+	}
+	return "", false
+}
+
+// All values for key, e.g. multiple "lcp:interface-config"
+func (p *Packet) GetAVPairValues(key string) (r []string) {
+	for _, av := range p.GetAVPairs() {
+		if av.Key == key {
+			r = append(r, av.Value)
+		}
+	}
+	return
+}
+
+func (p *Packet) AddAVPair(key, value string) error {
+	return p.AddAVPairFull(AVPair{Key: key, Value: value})
+}
+
+func (p *Packet) AddAVPairFull(av AVPair) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	if len(av.Key) == 0 || strings.ContainsAny(av.Key, "=*") {
+		return errInvalidFormat
+	}
+	return p.addVSAString(VendorCisco, CiscoAVPair, av.String())
+}
@@ -0,0 +1,190 @@
+package radius
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	VendorUSR     VendorID = 429
+	VendorLucent  VendorID = 4846
+	VendorStarent VendorID = 8164
+	VendorWiMAX   VendorID = 24757
+)
+
+const vsaMore = 0x80 // continuation flag, value continues in next VSA
+
+var errVSAType = errors.New("Vendor type out of range")
+
+// VSA header format (FreeRADIUS format=t,l), nil is 1 octet type and len
+type vendorFmt struct {
+	t byte // vendor type octets: 1, 2 or 4
+	l byte // vendor len octets: 0, 1 or 2, 0 - one sub-attr up to attr end
+	c bool // continuation byte after len (WiMAX)
+}
+
+// Builtin formats of known vendors, used when vendor is not in dictionary
+var stdVendorFmts = map[VendorID]*vendorFmt{
+	VendorUSR:     {t: 4, l: 0},
+	VendorLucent:  {t: 2, l: 1},
+	VendorStarent: {t: 2, l: 2},
+	VendorWiMAX:   {t: 1, l: 1, c: true},
+}
+
+// Put vendor VSA header format in dictionary, typeLen is 1, 2 or 4 octets,
+// lenLen is 0, 1 or 2 octets, cont adds continuation byte after len
+// (FreeRADIUS format=1,1,c). Vendors not in dictionary use 1,1 format.
+func AddVendorFormatFull(vid VendorID, typeLen, lenLen int, cont bool) error {
+	switch {
+	case typeLen != 1 && typeLen != 2 && typeLen != 4:
+		return errors.New("Invalid vendor type len")
+	case lenLen < 0 || lenLen > 2:
+		return errors.New("Invalid vendor len len")
+	case cont && lenLen == 0:
+		return errors.New("Continuation requires vendor len")
+	}
+	attrDict.Lock()
+	defer attrDict.Unlock()
+	if _, ok := attrDict.vendors[vid]; ok {
+		return errors.New("Vendor format exists")
+	}
+	attrDict.vendors[vid] = &vendorFmt{t: byte(typeLen), l: byte(lenLen), c: cont}
+	return nil
+}
+
+func MustAddVendorFormatFull(vid VendorID, typeLen, lenLen int, cont bool) {
+	if err := AddVendorFormatFull(vid, typeLen, lenLen, cont); err != nil {
+		panic(err)
+	}
+}
+
+func AddVendorFormat(vid VendorID, typeLen, lenLen int) error {
+	return AddVendorFormatFull(vid, typeLen, lenLen, false)
+}
+
+func MustAddVendorFormat(vid VendorID, typeLen, lenLen int) {
+	MustAddVendorFormatFull(vid, typeLen, lenLen, false)
+}
+
+// Vendor type and len octets of vendor VSA header
+func GetVendorFormat(vid VendorID) (typeLen, lenLen int) {
+	vf := vendorFormat(vid)
+	return vf.typeLen(), vf.lenLen()
+}
+
+func vendorFormat(vid VendorID) *vendorFmt {
+	attrDict.RLock()
+	vf, ok := attrDict.vendors[vid]
+	attrDict.RUnlock()
+	if ok {
+		return vf
+	}
+	return stdVendorFmts[vid]
+}
+
+func (vf *vendorFmt) typeLen() int {
+	if vf == nil {
+		return 1
+	}
+	return int(vf.t)
+}
+
+func (vf *vendorFmt) lenLen() int {
+	if vf == nil {
+		return 1
+	}
+	return int(vf.l)
+}
+
+func (vf *vendorFmt) cont() bool {
+	return vf != nil && vf.c
+}
+
+// vendor type, len and continuation octets
+func (vf *vendorFmt) hdrLen() int {
+	if vf.cont() {
+		return vf.typeLen() + vf.lenLen() + 1
+	}
+	return vf.typeLen() + vf.lenLen()
+}
+
+func (vf *vendorFmt) typeOK(vt VendorType) bool {
+	return vf.typeLen() > 1 || vt <= 0xff
+}
+
+// next sub-attr of VSA data after vendor ID, returns continuation flag
+// and rest of data
+func (vf *vendorFmt) next(b []byte) (vt VendorType, v []byte, more bool, rest []byte, err error) {
+	tl, hl := vf.typeLen(), vf.hdrLen()
+	if len(b) < hl {
+		err = errNoData
+		return
+	}
+	switch tl {
+	case 1:
+		vt = VendorType(b[0])
+	case 2:
+		vt = VendorType(binary.BigEndian.Uint16(b))
+	default:
+		t := binary.BigEndian.Uint32(b)
+		if t > 0xffff {
+			err = errVSAType
+			return
+		}
+		vt = VendorType(t)
+	}
+	n := len(b) // no len field, sub-attr takes the rest
+	switch vf.lenLen() {
+	case 1:
+		n = int(b[tl])
+	case 2:
+		n = int(binary.BigEndian.Uint16(b[tl:]))
+	}
+	switch {
+	case n < hl:
+		err = errInvalid
+	case n > len(b):
+		err = errNoData
+	default:
+		v, rest = b[hl:n], b[n:]
+		more = vf.cont() && b[hl-1]&vsaMore != 0
+	}
+	return
+}
+
+// append sub-attr header, n is sub-attr len including header
+func (vf *vendorFmt) appendHdr(b []byte, vt VendorType, n int) []byte {
+	switch vf.typeLen() {
+	case 1:
+		b = append(b, byte(vt))
+	case 2:
+		b = binary.BigEndian.AppendUint16(b, uint16(vt))
+	default:
+		b = binary.BigEndian.AppendUint32(b, uint32(vt))
+	}
+	switch vf.lenLen() {
+	case 1:
+		b = append(b, byte(n))
+	case 2:
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	}
+	if vf.cont() {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// check VSA data including vendor ID
+func checkVSA(b []byte) error {
+	if len(b) < 6 {
+		return errVSAShort
+	}
+	vf := vendorFormat(VendorID(binary.BigEndian.Uint32(b)))
+	for b = b[4:]; len(b) > 0; {
+		var err error
+		if _, _, _, b, err = vf.next(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
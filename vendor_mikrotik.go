@@ -0,0 +1,178 @@
+package radius
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	VendorMikrotik    VendorID   = 14988
+	MikrotikRateLimit VendorType = 8
+)
+
+var errRateLimit = errors.New("Invalid Mikrotik-Rate-Limit format")
+
+// Mikrotik-Rate-Limit value, rates in bits per second.
+// rx is client upload, tx is client download; 0 - not set.
+type RateLimit struct {
+	Rx, Tx                   uint64        // max rate
+	BurstRx, BurstTx         uint64        // burst rate
+	ThresholdRx, ThresholdTx uint64        // burst threshold
+	BurstTimeRx, BurstTimeTx time.Duration // burst time
+	Priority                 int           // 1-8
+	MinRx, MinTx             uint64        // limit-at
+}
+
+func parseRate(s string) (uint64, error) {
+	var mul uint64 = 1
+
+	if len(s) == 0 {
+		return 0, errRateLimit
+	}
+	switch s[len(s)-1] {
+	case 'k', 'K':
+		mul = 1000
+	case 'm', 'M':
+		mul = 1000000
+	case 'g', 'G':
+		mul = 1000000000
+	}
+	if mul != 1 {
+		s = s[:len(s)-1]
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, errRateLimit
+	}
+	return v * mul, nil
+}
+
+func formatRate(v uint64) string {
+	switch {
+	case v == 0:
+		return "0"
+	case v%1000000000 == 0:
+		return strconv.FormatUint(v/1000000000, 10) + "G"
+	case v%1000000 == 0:
+		return strconv.FormatUint(v/1000000, 10) + "M"
+	case v%1000 == 0:
+		return strconv.FormatUint(v/1000, 10) + "k"
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+func parseBurstTime(s string) (time.Duration, error) {
+	s = strings.TrimSuffix(s, "s")
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, errRateLimit
+	}
+	return time.Duration(v) * time.Second, nil
+}
+
+// parse "rx[/tx]" pair, tx defaults to rx
+func parsePair(s string, conv func(string) (uint64, error)) (rx, tx uint64, err error) {
+	r, t, ok := strings.Cut(s, "/")
+	if rx, err = conv(r); err != nil {
+		return
+	}
+	if !ok {
+		tx = rx
+		return
+	}
+	tx, err = conv(t)
+	return
+}
+
+func ParseRateLimit(s string) (*RateLimit, error) {
+	var err error
+
+	f := strings.Fields(s)
+	if len(f) == 0 || len(f) > 6 {
+		return nil, errRateLimit
+	}
+	rl := &RateLimit{}
+	if rl.Rx, rl.Tx, err = parsePair(f[0], parseRate); err != nil {
+		return nil, err
+	}
+	if len(f) > 1 {
+		if rl.BurstRx, rl.BurstTx, err = parsePair(f[1], parseRate); err != nil {
+			return nil, err
+		}
+	}
+	if len(f) > 2 {
+		if rl.ThresholdRx, rl.ThresholdTx, err = parsePair(f[2], parseRate); err != nil {
+			return nil, err
+		}
+	}
+	if len(f) > 3 {
+		var rx, tx uint64
+		rx, tx, err = parsePair(f[3], func(s string) (uint64, error) {
+			d, err := parseBurstTime(s)
+			return uint64(d), err
+		})
+		if err != nil {
+			return nil, err
+		}
+		rl.BurstTimeRx, rl.BurstTimeTx = time.Duration(rx), time.Duration(tx)
+	}
+	if len(f) > 4 {
+		if rl.Priority, err = strconv.Atoi(f[4]); err != nil || rl.Priority < 1 || rl.Priority > 8 {
+			return nil, errRateLimit
+		}
+	}
+	if len(f) > 5 {
+		if rl.MinRx, rl.MinTx, err = parsePair(f[5], parseRate); err != nil {
+			return nil, err
+		}
+	}
+	return rl, nil
+}
+
+func (rl *RateLimit) String() string {
+	f := []string{
+		formatRate(rl.Rx) + "/" + formatRate(rl.Tx),
+		formatRate(rl.BurstRx) + "/" + formatRate(rl.BurstTx),
+		formatRate(rl.ThresholdRx) + "/" + formatRate(rl.ThresholdTx),
+		strconv.FormatInt(int64(rl.BurstTimeRx/time.Second), 10) + "/" +
+			strconv.FormatInt(int64(rl.BurstTimeTx/time.Second), 10),
+		strconv.Itoa(rl.Priority),
+		formatRate(rl.MinRx) + "/" + formatRate(rl.MinTx),
+	}
+	// cut unset trailing fields
+	n := 1
+	switch {
+	case rl.MinRx != 0 || rl.MinTx != 0:
+		n = 6
+		if rl.Priority == 0 {
+			f[4] = "8" // default priority
+		}
+	case rl.Priority != 0:
+		n = 5
+	case rl.BurstTimeRx != 0 || rl.BurstTimeTx != 0:
+		n = 4
+	case rl.ThresholdRx != 0 || rl.ThresholdTx != 0:
+		n = 3
+	case rl.BurstRx != 0 || rl.BurstTx != 0:
+		n = 2
+	}
+	return strings.Join(f[:n], " ")
+}
+
+// Get and parse first Mikrotik-Rate-Limit
+func (p *Packet) GetRateLimit() (*RateLimit, error) {
+	b, ok := p.getVSAData(VendorMikrotik, MikrotikRateLimit)
+	if !ok {
+		return nil, errors.New("Mikrotik-Rate-Limit not found")
+	}
+	return ParseRateLimit(string(b))
+}
+
+func (p *Packet) AddRateLimit(rl *RateLimit) error {
+	if p == nil {
+		return errors.New("Packet empty")
+	}
+	return p.addVSAString(VendorMikrotik, MikrotikRateLimit, rl.String())
+}
@@ -0,0 +1,32 @@
+package radius
+
+// Tracks VSA being written, so next VSA of the same vendor can be put
+// in it as long as it fits in 255 bytes
+type vsaPacker struct {
+	vid VendorID // vendor of current VSA
+	n   int      // len of current VSA
+	ok  bool     // current VSA can take more
+}
+
+// true if attr joins current VSA, otherwise attr starts new one
+func (vp *vsaPacker) add(a *Attr) bool {
+	if !a.IsVSA() || a.raw != nil || a.cont || a.vf.lenLen() == 0 { // wire form is kept as is
+		vp.ok = false
+		return false
+	}
+	wl := a.wireLen()
+	if vp.ok && a.vid == vp.vid && vp.n+wl-6 <= 255 {
+		vp.n += wl - 6
+		return true
+	}
+	vp.vid, vp.n, vp.ok = a.vid, wl, wl <= 255
+	return false
+}
+
+// Pack VSAs of the same vendor in a row into one attr on serialize
+func (p *Packet) SetPackVSA(on bool) {
+	if p == nil {
+		return
+	}
+	p.pack = on
+}
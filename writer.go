@@ -0,0 +1,210 @@
+package radius
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+var (
+	errAttrTooLong = errors.New("Attribute too long")
+	errPktTooLong  = errors.New("Packet too long")
+)
+
+// Streaming packet writer, appends attributes directly to output buffer.
+// Errors are sticky and returned by Finish.
+type Writer struct {
+	buf  []byte    // output buffer
+	off  int       // packet start in buf
+	max  int       // max packet len
+	err  error     // first error
+	Rand io.Reader // Random source for Request Authenticator, nil - crypto provider
+}
+
+// Start packet at the end of buf (buf may be nil or reused buf[:0])
+func NewWriter(buf []byte, code RadiusCode, id byte) *Writer {
+	w := &Writer{
+		off: len(buf),
+		max: MaxPLen,
+	}
+	w.buf = append(buf, byte(code), id, 0, 0)
+	w.buf = append(w.buf, zeroAuth[:]...)
+	return w
+}
+
+// Set max packet len (MaxLongLen for TCP/TLS)
+func (w *Writer) SetMaxLen(n int) {
+	if n < MinPLen || n > MaxLongLen {
+		n = MaxPLen
+	}
+	w.max = n
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) Len() int {
+	return len(w.buf) - w.off
+}
+
+func (w *Writer) grow(n int) bool {
+	if w.err != nil {
+		return false
+	}
+	if w.Len()+n > w.max {
+		w.err = errPktTooLong
+		return false
+	}
+	return true
+}
+
+// Add attr with raw value, tag is prepended for tagged attrs
+func (w *Writer) addAttr(t AttrType, tagged bool, tag byte, v []byte) {
+	n := len(v) + 2
+	if tagged {
+		n++
+	}
+	if n > 255 {
+		w.err = errAttrTooLong
+		return
+	}
+	if !w.grow(n) {
+		return
+	}
+	w.buf = append(w.buf, byte(t), byte(n))
+	if tagged {
+		w.buf = append(w.buf, tag)
+	}
+	w.buf = append(w.buf, v...)
+}
+
+func (w *Writer) AddRaw(t AttrType, v []byte) {
+	w.addAttr(t, false, 0, v)
+}
+
+func (w *Writer) AddTagged(t AttrType, tag byte, v []byte) {
+	w.addAttr(t, true, tag, v)
+}
+
+func (w *Writer) AddString(t AttrType, v string) {
+	n := len(v) + 2
+	if n > 255 {
+		w.err = errAttrTooLong
+		return
+	}
+	if !w.grow(n) {
+		return
+	}
+	w.buf = append(w.buf, byte(t), byte(n))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *Writer) AddUint32(t AttrType, v uint32) {
+	if !w.grow(6) {
+		return
+	}
+	w.buf = binary.BigEndian.AppendUint32(append(w.buf, byte(t), 6), v)
+}
+
+func (w *Writer) AddUint64(t AttrType, v uint64) {
+	if !w.grow(10) {
+		return
+	}
+	w.buf = binary.BigEndian.AppendUint64(append(w.buf, byte(t), 10), v)
+}
+
+// IPv4 or IPv6 address by attr
+func (w *Writer) AddIP(t AttrType, ip net.IP) {
+	if v4 := ip.To4(); v4 != nil {
+		w.AddRaw(t, v4)
+		return
+	}
+	if len(ip) != net.IPv6len {
+		if w.err == nil {
+			w.err = errInvalidFormat
+		}
+		return
+	}
+	w.AddRaw(t, ip)
+}
+
+func (w *Writer) AddTime(t AttrType, v time.Time) {
+	u := v.Unix()
+	if u < 0 || u > 0xffffffff {
+		if w.err == nil {
+			w.err = errInvalidFormat
+		}
+		return
+	}
+	w.AddUint32(t, uint32(u))
+}
+
+// VSA with one sub-attribute
+func (w *Writer) AddVSA(vid VendorID, vtype VendorType, v []byte) {
+	vf := vendorFormat(vid)
+	if !vf.typeOK(vtype) {
+		w.err = errVSAType
+		return
+	}
+	n := len(v) + 6 + vf.hdrLen()
+	if n > 255 {
+		w.err = errAttrTooLong
+		return
+	}
+	if !w.grow(n) {
+		return
+	}
+	w.buf = append(w.buf, byte(AttrVSA), byte(n))
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(vid))
+	w.buf = vf.appendHdr(w.buf, vtype, n-6)
+	w.buf = append(w.buf, v...)
+}
+
+func (w *Writer) pkt() []byte {
+	return w.buf[w.off:]
+}
+
+func (w *Writer) finishLen() []byte {
+	p := w.pkt()
+	binary.BigEndian.PutUint16(p[2:], uint16(len(p)))
+	return p
+}
+
+// Finish request packet, returns buf with packet appended.
+// Access-Request and Status-Server get random Request Authenticator,
+// Accounting and CoA/Disconnect requests get MD5 over packet and secret.
+func (w *Writer) Finish(secret []byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	p := w.finishLen()
+	switch RadiusCode(p[0]) {
+	case AccountingRequest, DisconnectRequest, CoARequest:
+		copy(p[4:MinPLen], calcAuth(p, zeroAuth[:], secret))
+	default:
+		r := w.Rand
+		if r == nil {
+			r = GetCryptoProvider().Rand()
+		}
+		if _, err := io.ReadFull(r, p[4:MinPLen]); err != nil {
+			return nil, err
+		}
+	}
+	return w.buf, nil
+}
+
+// Finish response packet, reqAuth is Authenticator of request
+func (w *Writer) FinishResponse(secret, reqAuth []byte) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	if len(reqAuth) != authLen {
+		return nil, errors.New("Invalid request authenticator")
+	}
+	p := w.finishLen()
+	copy(p[4:MinPLen], calcAuth(p, reqAuth, secret))
+	return w.buf, nil
+}